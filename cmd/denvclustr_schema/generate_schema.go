@@ -11,11 +11,17 @@ import (
 
 func main() {
 	var outputFile string
+	var version string
 	flag.StringVar(&outputFile, "out", "", "Output JSON schema file (default: stdout).")
+	flag.StringVar(&version, "version", shema.CurrentVersion, "Version of the denvclustr file format.")
 	flag.Parse()
 
 	// Get the JSON schema
-	schema := shema.GetSchema()
+	schema, err := shema.GetSchemaForVersion(version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting schema: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Marshal the schema to JSON with indentation
 	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
@@ -20,9 +20,9 @@ var deployCmd = &cobra.Command{
 	Short: "Deploy devcontainers from a denvclustr file",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		inputFile := "denvclustr.json"
-		if len(args) > 0 {
-			inputFile = args[0]
+		inputFile, err := resolveInputFile(args)
+		if err != nil {
+			return err
 		}
 
 		if planOnly {
@@ -37,9 +37,9 @@ var destroyCmd = &cobra.Command{
 	Short: "Destroy devcontainers deployed from a denvclustr file",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		inputFile := "denvclustr.json"
-		if len(args) > 0 {
-			inputFile = args[0]
+		inputFile, err := resolveInputFile(args)
+		if err != nil {
+			return err
 		}
 
 		if planOnly {
@@ -54,9 +54,13 @@ var generateCmd = &cobra.Command{
 	Short: "Generate Terraform HCL from a denvclustr file",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		inputFile := "denvclustr.json"
-		if len(args) > 0 {
-			inputFile = args[0]
+		inputFile, err := resolveInputFile(args)
+		if err != nil {
+			return err
+		}
+
+		if printConfig {
+			return printEffectiveConfig(inputFile, false)
 		}
 
 		// If output file not specified, use current directory + denvclustr.tf
@@ -68,13 +72,90 @@ var generateCmd = &cobra.Command{
 			outputFile = filepath.Join(currentDir, "denvclustr.tf")
 		}
 
-		return generateHcl(inputFile, outputFile)
+		return generateHcl(inputFile, outputFile, strictChecks)
+	},
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate a denvclustr file without generating or deploying anything",
+	Long: `Validate a denvclustr file against the JSON schema and the semantic rules.
+Every problem is reported at once, in a human readable, JSON or SARIF format.
+The command exits with a non-zero status if any error is found.`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputFile, err := resolveInputFile(args)
+		if err != nil {
+			return err
+		}
+
+		return validateFile(inputFile, reportFormat)
 	},
 }
+
+var showConfigCmd = &cobra.Command{
+	Use:   "show-config [file]",
+	Short: "Print the effective configuration of a denvclustr file",
+	Long: `Print the effective configuration of a denvclustr file, in the format of the file:
+included files and the overlay of --env are merged, variables are resolved and defaults
+are applied, such as the OpenVSCode Server of devcontainers without remote access.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputFile, err := resolveInputFile(args)
+		if err != nil {
+			return err
+		}
+
+		return printEffectiveConfig(inputFile, showRaw)
+	},
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [file]",
+	Short: "Upgrade a denvclustr file to the current file format version",
+	Long: `Upgrade a denvclustr file to the current file format version.
+The file is rewritten in place, or the changes are printed as a diff with --diff.
+JSONC files lose their comments when rewritten, so they are only rewritten with --force.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputFile, err := resolveInputFile(args)
+		if err != nil {
+			return err
+		}
+
+		return migrateFile(inputFile, showDiff, forceMigrate)
+	},
+}
+
+var reapCmd = &cobra.Command{
+	Use:   "reap [working-dir...]",
+	Short: "Destroy expired clusters and remove expired devcontainers",
+	Long: `Destroy the clusters deployed from the given working directories, or from every working
+directory deployed from if none is given, once their expires_at or ttl passed. Expired devcontainers
+are removed from clusters that did not expire, and clusters whose devcontainers all expired are destroyed.
+Nothing is confirmed, so the command can run unattended: every action is appended to the audit log.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reapWorkingDirs(args, auditLogFile, planOnly)
+	},
+}
+
 var (
-	outputFile string
-	planOnly   bool
-	workingDir string
+	auditLogFile   string
+	outputFile     string
+	reportFormat   string
+	showDiff       bool
+	forceMigrate   bool
+	planOnly       bool
+	workingDir     string
+	variableValues []string
+	variableFiles  []string
+	environment    string
+	catalogFile    string
+	printConfig    bool
+	strictChecks   bool
+	showRaw        bool
 )
 
 func init() {
@@ -86,9 +167,33 @@ func init() {
 	destroyCmd.Flags().BoolVarP(&planOnly, "plan", "p", false, "Show destroy plan without applying changes")
 	destroyCmd.Flags().StringVarP(&workingDir, "working-dir", "w", "output", "Working directory for Terraform operations")
 
+	reapCmd.Flags().BoolVarP(&planOnly, "plan", "p", false, "Show what would be destroyed and removed without applying changes")
+	reapCmd.Flags().StringVar(&auditLogFile, "audit-log", "", "File the actions are appended to, as JSON lines (default: reap.log in the denvclustr user configuration directory)")
+
+	validateCmd.Flags().StringVarP(&reportFormat, "format", "f", reportFormatHuman, "Report format: human, json or sarif")
+
+	migrateCmd.Flags().BoolVarP(&showDiff, "diff", "d", false, "Print the changes as a unified diff instead of rewriting the file")
+	migrateCmd.Flags().BoolVar(&forceMigrate, "force", false, "Rewrite JSONC files even though their comments are lost")
+
+	generateCmd.Flags().BoolVar(&strictChecks, "strict", false, "Also check the public SSH key files, as deploy does")
+	generateCmd.Flags().BoolVar(&printConfig, "print-config", false, "Print the effective configuration, like show-config, instead of generating Terraform HCL")
+
+	showConfigCmd.Flags().BoolVar(&showRaw, "raw", false, "Print the configuration without applying defaults")
+
+	for _, cmd := range []*cobra.Command{generateCmd, deployCmd, destroyCmd, validateCmd, showConfigCmd} {
+		cmd.Flags().StringVarP(&environment, "env", "e", "", "Merge the overlay of an environment, e.g. denvclustr.staging.json for --env staging")
+		cmd.Flags().StringArrayVar(&variableValues, "var", nil, "Set a variable declared in the denvclustr file (NAME=VALUE, can be repeated)")
+		cmd.Flags().StringArrayVar(&variableFiles, "var-file", nil, "Read variable values from a JSON or YAML file (can be repeated)")
+		cmd.Flags().StringVar(&catalogFile, "catalog", "", "Add the regions and instance types of a JSON or YAML catalog file to the embedded catalog")
+	}
+
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(deployCmd)
 	rootCmd.AddCommand(destroyCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(showConfigCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(reapCmd)
 }
 
 func Execute() error {
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -25,8 +27,8 @@ func showPlan(inputFile, workDirPath string) error {
 	slog.Info("Showing deployment plan", "input", inputFile)
 
 	// Process the input file
-	// We don't need the root configuration for showPlan, but we need the HCL file
-	_, hclFile, err := processInputFile(inputFile)
+	// The root configuration is used for the summary of the nodes shown with the plan
+	root, hclFile, err := processInputFile(inputFile)
 	if err != nil {
 		return err
 	}
@@ -93,6 +95,8 @@ func showPlan(inputFile, workDirPath string) error {
 		} else {
 			displayResourceChanges(plan)
 		}
+		fmt.Println()
+		printNodeMarkets(os.Stdout, root)
 	}
 
 	fmt.Printf("\nTo apply this plan, run: denvclustr deploy %s -w %s\n", inputFile, workDirPath)
@@ -109,11 +113,12 @@ func deployDevcontainers(inputFile, workDirPath string) error {
 
 	slog.Info("Deploying devcontainers", "input", inputFile)
 
-	// Process the input file
-	root, hclFile, err := processInputFile(inputFile)
+	// Process the input file, checking the public SSH keys before anything is applied
+	root, hclFile, err := processInputFile(inputFile, schema.WithPublicKeyCheck())
 	if err != nil {
 		return err
 	}
+	printPublicKeys(os.Stdout, root)
 
 	// Get current directory
 	currentDir, err := os.Getwd()
@@ -164,7 +169,12 @@ func deployDevcontainers(inputFile, workDirPath string) error {
 
 	if !hasChanges {
 		fmt.Println("No changes to apply. Infrastructure is up-to-date.")
-		return nil
+		// The expiries still count from the last deployment, if any was recorded
+		deployedAt := time.Now()
+		if previous, err := readMetadata(workingDir); err == nil {
+			deployedAt = previous.DeployedAt
+		}
+		return recordDeployment(inputFile, workingDir, root, deployedAt)
 	}
 
 	// Display plan details
@@ -178,6 +188,8 @@ func deployDevcontainers(inputFile, workDirPath string) error {
 	} else {
 		displayResourceChanges(plan)
 	}
+	fmt.Println()
+	printNodeMarkets(os.Stdout, root)
 
 	fmt.Println("\nProceeding with deployment...")
 
@@ -202,6 +214,10 @@ func deployDevcontainers(inputFile, workDirPath string) error {
 
 	fmt.Println("\nDeployment completed successfully!")
 
+	if err := recordDeployment(inputFile, workingDir, root, time.Now()); err != nil {
+		return err
+	}
+
 	// Get and display the outputs
 	outputs, err := tf.Output(ctx)
 	if err != nil {
@@ -218,6 +234,33 @@ func deployDevcontainers(inputFile, workDirPath string) error {
 	return nil
 }
 
+// recordDeployment writes the metadata of the working directory and adds it to the known working
+// directories, so 'denvclustr reap' destroys the cluster and its devcontainers once they expire.
+func recordDeployment(inputFile, workingDir string, root *schema.DenvclustrRoot, deployedAt time.Time) error {
+	metadata, err := newWorkingDirMetadata(inputFile, root, deployedAt)
+	if err != nil {
+		return err
+	}
+	if err := writeMetadata(workingDir, metadata); err != nil {
+		return err
+	}
+	if err := registerWorkingDir(workingDir); err != nil {
+		return err
+	}
+
+	if metadata.ExpiresAt != nil {
+		fmt.Printf("\n⌛ Cluster %s expires on %s\n", metadata.Name, metadata.ExpiresAt.Local().Format(scheduleTimeFormat))
+	}
+	for _, id := range slices.Sorted(maps.Keys(metadata.Devcontainers)) {
+		fmt.Printf("⌛ Devcontainer %s expires on %s\n", id, metadata.Devcontainers[id].Local().Format(scheduleTimeFormat))
+	}
+	return nil
+}
+
+// scheduleTimeFormat formats the times shown after a deployment: when nodes are stopped and started, in the
+// time zone of their schedule, and when the cluster and its devcontainers expire, in the local time zone.
+const scheduleTimeFormat = "Mon Jan 2 15:04 MST"
+
 // displayDeploymentOutputs formats and displays the deployment outputs in a user-friendly way
 func displayDeploymentOutputs(outputs map[string]tfexec.OutputMeta, root *schema.DenvclustrRoot) error {
 	if len(outputs) == 0 {
@@ -244,7 +287,24 @@ func displayDeploymentOutputs(outputs map[string]tfexec.OutputMeta, root *schema
 		slog.Warn("AWS region not found in configuration. Cannot retrieve tokens from SSM.")
 	}
 
-	for _, output := range outputs {
+	// Devcontainers by id, to show the revision each one is deployed from
+	devcontainersById := make(map[string]*schema.Devcontainer)
+	// Nodes by id and regions by infrastructure id, to show SSH commands for the access mode of each node
+	nodesById := make(map[string]*schema.Node)
+	regionsById := make(map[string]string)
+	if root != nil {
+		for _, devcontainer := range root.Devcontainers {
+			devcontainersById[string(devcontainer.Id)] = devcontainer
+		}
+		for _, node := range root.Nodes {
+			nodesById[string(node.Id)] = node
+		}
+		for _, infra := range root.Infrastructure {
+			regionsById[string(infra.Id)] = string(infra.Region)
+		}
+	}
+
+	for name, output := range outputs {
 		var outputData map[string]any
 		if err := json.Unmarshal([]byte(output.Value), &outputData); err != nil {
 			return fmt.Errorf("failed to unmarshal outputs: %w", err)
@@ -252,11 +312,37 @@ func displayDeploymentOutputs(outputs map[string]tfexec.OutputMeta, root *schema
 
 		module := outputData["module"].(map[string]any)
 		devcontainers := module["devcontainers"].([]any)
+		node := nodesById[strings.TrimSuffix(name, "_output")]
+		instanceId, _ := module["instance_id"].(string)
+		nodeRegion := awsRegion
+		if node != nil && regionsById[string(node.InfrastructureId)] != "" {
+			nodeRegion = regionsById[string(node.InfrastructureId)]
+		}
+		if node != nil && node.Schedule != nil {
+			stop, err := node.Schedule.NextStop(time.Now())
+			if err == nil {
+				var start time.Time
+				if start, err = node.Schedule.NextStart(stop); err == nil {
+					fmt.Printf("\n⏰ Node %s stops on %s and starts again on %s\n", node.Id, stop.Format(scheduleTimeFormat), start.Format(scheduleTimeFormat))
+				}
+			}
+			if err != nil {
+				slog.Warn("Cannot compute the next stop of the node", "node", node.Id, "error", err)
+			}
+		}
 
 		for _, devcontainer := range devcontainers {
 			devcontainerMap := devcontainer.(map[string]any)
 			id := devcontainerMap["id"].(string)
 			fmt.Printf("\n📦 Devcontainer %s:\n", id)
+			if deployed, ok := devcontainersById[id]; ok {
+				if kind, revision := deployed.Source.Revision(); kind != "" {
+					fmt.Printf("  📌 Source: %s at %s %s\n", deployed.Source.URL, kind, revision)
+				}
+				if deployed.Image != nil {
+					fmt.Printf("  📌 Image: %s\n", deployed.Image.Reference)
+				}
+			}
 			remote_access := devcontainerMap["remote_access"].(map[string]any)
 
 			if remote_access["openvscode_server"] != nil {
@@ -290,7 +376,11 @@ func displayDeploymentOutputs(outputs map[string]tfexec.OutputMeta, root *schema
 
 			if remote_access["ssh"] != nil {
 				ssh := remote_access["ssh"].(map[string]any)
-				fmt.Printf("  🔑 SSH Access: %s\n", ssh["command"])
+				command, _ := ssh["command"].(string)
+				fmt.Printf("  🔑 SSH Access: %s\n", sshCommand(command, node, instanceId, nodeRegion))
+				if node != nil && node.RemoteAccess.AccessMode() == schema.AccessModeSSMSession {
+					fmt.Printf("  ℹ️  Requires the AWS CLI with the Session Manager plugin\n")
+				}
 			}
 		}
 	}
@@ -105,15 +105,33 @@ func destroyDevcontainers(inputFile, workDirPath string) error {
 	// Get full working directory path
 	workingDir := filepath.Join(currentDir, workDirPath)
 
+	destroyed, err := destroyWorkingDir(workingDir, true)
+	if err != nil {
+		return err
+	}
+	if destroyed {
+		// Nothing is left for 'denvclustr reap' to destroy
+		if err := unregisterWorkingDir(workingDir); err != nil {
+			slog.Warn("Failed to unregister working directory", "path", workingDir, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// destroyWorkingDir destroys the resources deployed from a working directory. When interactive,
+// the destroy plan must be confirmed first. It reports whether no resources are left, i.e.
+// whether they were destroyed or there was nothing to destroy, as opposed to the user cancelling.
+func destroyWorkingDir(workingDir string, interactive bool) (bool, error) {
 	// Check if working directory exists
 	if _, err := os.Stat(workingDir); os.IsNotExist(err) {
-		return fmt.Errorf("working directory not found: %s", workingDir)
+		return false, fmt.Errorf("working directory not found: %s", workingDir)
 	}
 
 	// Check if Terraform state exists
 	statePath := filepath.Join(workingDir, "terraform.tfstate")
 	if _, err := os.Stat(statePath); os.IsNotExist(err) {
-		return fmt.Errorf("terraform state not found in %s - nothing to destroy", workingDir)
+		return false, fmt.Errorf("terraform state not found in %s - nothing to destroy", workingDir)
 	}
 
 	slog.Info("Using working directory for Terraform operations", "path", workingDir)
@@ -121,7 +139,7 @@ func destroyDevcontainers(inputFile, workDirPath string) error {
 	// Initialize Terraform
 	tf, err := tfexec.NewTerraform(workingDir, "terraform")
 	if err != nil {
-		return fmt.Errorf("failed to initialize terraform: %w", err)
+		return false, fmt.Errorf("failed to initialize terraform: %w", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
@@ -131,7 +149,7 @@ func destroyDevcontainers(inputFile, workDirPath string) error {
 	fmt.Println("Initializing Terraform...")
 	err = tf.Init(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to run terraform init: %w", err)
+		return false, fmt.Errorf("failed to run terraform init: %w", err)
 	}
 
 	// First show the destroy plan
@@ -140,12 +158,12 @@ func destroyDevcontainers(inputFile, workDirPath string) error {
 
 	hasChanges, err := tf.Plan(ctx, tfexec.Out(planFilePath), tfexec.Destroy(true))
 	if err != nil {
-		return fmt.Errorf("failed to run terraform destroy plan: %w", err)
+		return false, fmt.Errorf("failed to run terraform destroy plan: %w", err)
 	}
 
 	if !hasChanges {
 		fmt.Println("No resources to destroy. Infrastructure is empty.")
-		return nil
+		return true, nil
 	}
 
 	// Display plan details
@@ -161,26 +179,28 @@ func destroyDevcontainers(inputFile, workDirPath string) error {
 	}
 
 	// Confirm before destroying
-	fmt.Println("\nWARNING: This will destroy all resources shown above.")
-	fmt.Println("You cannot recover from this operation.")
-	fmt.Print("Do you want to proceed? (yes/no): ")
-
-	var response string
-	fmt.Scanln(&response)
-	if response != "yes" {
-		fmt.Println("Destroy operation cancelled.")
-		return nil
+	if interactive {
+		fmt.Println("\nWARNING: This will destroy all resources shown above.")
+		fmt.Println("You cannot recover from this operation.")
+		fmt.Print("Do you want to proceed? (yes/no): ")
+
+		var response string
+		fmt.Scanln(&response)
+		if response != "yes" {
+			fmt.Println("Destroy operation cancelled.")
+			return false, nil
+		}
 	}
 
 	fmt.Println("\nDestroying resources...")
 
 	err = tf.Destroy(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to run terraform destroy: %w", err)
+		return false, fmt.Errorf("failed to run terraform destroy: %w", err)
 	}
 
 	fmt.Printf("\nAll resources have been successfully destroyed!\n")
 	fmt.Printf("Terraform files are still preserved in: %s\n", workingDir)
 
-	return nil
+	return true, nil
 }
@@ -5,16 +5,28 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+
+	"github.com/tropicaltux/denvclustr/pkg/schema"
 )
 
-func generateHcl(inputFile, outputFile string) error {
-	slog.Info("Generating Terraform HCL", "input", inputFile, "output", outputFile)
+// generateHcl writes the Terraform HCL of a denvclustr file. With strict, the public SSH key
+// files are checked too, as they are before a deployment.
+func generateHcl(inputFile, outputFile string, strict bool) error {
+	slog.Info("Generating Terraform HCL", "input", inputFile, "output", outputFile, "strict", strict)
+
+	var options []schema.Option
+	if strict {
+		options = append(options, schema.WithPublicKeyCheck())
+	}
 
 	// Process the input file
-	_, hclFile, err := processInputFile(inputFile)
+	root, hclFile, err := processInputFile(inputFile, options...)
 	if err != nil {
 		return err
 	}
+	if strict {
+		printPublicKeys(os.Stdout, root)
+	}
 
 	// Create output directory if it doesn't exist
 	outDir := filepath.Dir(outputFile)
@@ -3,9 +3,13 @@ package denvclustr
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -16,22 +20,151 @@ import (
 	"github.com/tropicaltux/denvclustr/pkg/schema"
 )
 
-// processInputFile reads, parses, and converts a denvclustr JSON file to HCL.
-// It returns the parsed configuration and HCL content, or an error if any step fails.
-func processInputFile(inputFile string) (*schema.DenvclustrRoot, *hclwrite.File, error) {
+// resolveInputFile returns the denvclustr file given on the command line.
+// Without an argument it looks for one of the default denvclustr files in the current directory.
+func resolveInputFile(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	var found []string
+	for _, name := range schema.DefaultFileNames {
+		if _, err := os.Stat(name); err == nil {
+			found = append(found, name)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("input file not found: none of %s exists in the current directory", strings.Join(schema.DefaultFileNames, ", "))
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("multiple denvclustr files found (%s), specify which one to use", strings.Join(found, ", "))
+	}
+}
+
+// readInputFile checks that the input file exists and returns its content.
+func readInputFile(inputFile string) ([]byte, error) {
 	// Check if input file exists
 	if _, err := os.Stat(inputFile); os.IsNotExist(err) {
-		return nil, nil, fmt.Errorf("input file not found: %s", inputFile)
+		return nil, fmt.Errorf("input file not found: %s", inputFile)
 	}
 
 	// Read the input file
 	data, err := os.ReadFile(inputFile)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read input file: %w", err)
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+	return data, nil
+}
+
+// resolveOverlayFile returns the overlay of the input file for an environment, e.g.
+// denvclustr.staging.yaml for denvclustr.json and the staging environment.
+// The overlay may be written in any supported format.
+func resolveOverlayFile(inputFile, env string) (string, error) {
+	if strings.ContainsAny(env, `/\.`) {
+		return "", fmt.Errorf("invalid environment name %q", env)
+	}
+
+	stem := strings.TrimSuffix(inputFile, filepath.Ext(inputFile))
+	var candidates, found []string
+	for _, name := range schema.DefaultFileNames {
+		candidate := stem + "." + env + filepath.Ext(name)
+		candidates = append(candidates, candidate)
+		if _, err := os.Stat(candidate); err == nil {
+			found = append(found, candidate)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("overlay for environment %q not found: none of %s exists", env, strings.Join(candidates, ", "))
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("multiple overlays found for environment %q (%s)", env, strings.Join(found, ", "))
+	}
+}
+
+// parseOptions returns the options used to parse a denvclustr file: its format, its path, which
+// its includes are relative to, the overlay selected with --env, the catalog given with --catalog
+// and the variable values given with --var-file and --var.
+// Values given with --var take precedence.
+func parseOptions(inputFile string, data []byte) ([]schema.Option, error) {
+	options := []schema.Option{
+		schema.WithFormat(schema.DetectFormat(inputFile, data)),
+		schema.WithPath(inputFile),
+	}
+
+	if environment != "" {
+		overlayFile, err := resolveOverlayFile(inputFile, environment)
+		if err != nil {
+			return nil, err
+		}
+		overlay, err := readInputFile(overlayFile)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("Applying overlay", "environment", environment, "overlay", overlayFile)
+		options = append(options, schema.WithOverlay(overlayFile, overlay, schema.DetectFormat(overlayFile, overlay)))
+	}
+
+	if catalogFile != "" {
+		content, err := os.ReadFile(catalogFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read catalog file: %w", err)
+		}
+		catalog, err := schema.ParseCatalog(content, schema.DetectFormat(catalogFile, content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse catalog file %s: %w", catalogFile, err)
+		}
+		options = append(options, schema.WithCatalog(catalog))
+	}
+
+	for _, path := range variableFiles {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read variables file: %w", err)
+		}
+		values, err := schema.ParseVariables(content, schema.DetectFormat(path, content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse variables file %s: %w", path, err)
+		}
+		options = append(options, schema.WithVariables(values))
+	}
+
+	values := make(map[string]string)
+	for _, assignment := range variableValues {
+		name, value, found := strings.Cut(assignment, "=")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected NAME=VALUE", assignment)
+		}
+		values[strings.TrimSpace(name)] = value
+	}
+	options = append(options, schema.WithVariables(values))
+
+	return options, nil
+}
+
+// processInputFile reads, parses, and converts a denvclustr file to HCL.
+// The file format (JSON, JSONC or YAML) is detected from its extension and content,
+// the overlay of the --env flag is merged and variables are resolved from the --var and --var-file flags.
+// The extra options enable additional checks, such as schema.WithPublicKeyCheck.
+// It returns the parsed configuration and HCL content, or an error if any step fails.
+func processInputFile(inputFile string, extra ...schema.Option) (*schema.DenvclustrRoot, *hclwrite.File, error) {
+	data, err := readInputFile(inputFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options, err := parseOptions(inputFile, data)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Parse the denvclustr file
-	root, err := schema.Parse(data)
+	root, err := schema.Parse(data, append(options, extra...)...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse denvclustr file: %w", err)
 	}
@@ -45,6 +178,40 @@ func processInputFile(inputFile string) (*schema.DenvclustrRoot, *hclwrite.File,
 	return root, hclFile, nil
 }
 
+// printPublicKeys prints the type and fingerprint of the public SSH keys of a configuration,
+// so they can be compared with the keys the user expects to log in with.
+// Unreadable keys are skipped, since schema.WithPublicKeyCheck reports them.
+func printPublicKeys(w io.Writer, root *schema.DenvclustrRoot) {
+	for _, path := range schema.PublicKeyPaths(root) {
+		key, err := schema.ReadPublicKey(path)
+		if err != nil {
+			continue
+		}
+		slog.Info("Public SSH key", "path", key.Path, "type", key.Type, "fingerprint", key.Fingerprint)
+		fmt.Fprintf(w, "🔑 %s: %s %s\n", key.Path, key.Type, key.Fingerprint)
+	}
+}
+
+// printNodeMarkets prints the purchasing option of every node, so spot nodes are noticed before they are deployed.
+func printNodeMarkets(w io.Writer, root *schema.DenvclustrRoot) {
+	if root == nil {
+		return
+	}
+	for _, node := range root.Nodes {
+		properties := node.Properties
+		market := properties.MarketType()
+		if market != schema.MarketSpot {
+			fmt.Fprintf(w, "💰 Node %s: %s %s\n", node.Id, properties.InstanceType, market)
+			continue
+		}
+		maxPrice := "on-demand price"
+		if properties.MaxPrice != nil {
+			maxPrice = fmt.Sprintf("$%s/hour", strconv.FormatFloat(*properties.MaxPrice, 'f', -1, 64))
+		}
+		fmt.Fprintf(w, "💰 Node %s: %s spot, up to %s, %s on interruption\n", node.Id, properties.InstanceType, maxPrice, properties.SpotInterruptionBehavior())
+	}
+}
+
 // checkTerraformInstalled verifies that the Terraform CLI is available
 func checkTerraformInstalled() error {
 	// Execute a simple command to check if terraform is available
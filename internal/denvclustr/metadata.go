@@ -0,0 +1,208 @@
+package denvclustr
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tropicaltux/denvclustr/pkg/schema"
+)
+
+// metadataFileName is the file of a working directory describing what was deployed from it.
+const metadataFileName = "denvclustr-metadata.json"
+
+// workingDirMetadata is written to a working directory at deploy time, so 'denvclustr reap'
+// can find out when the cluster and its devcontainers expire without the denvclustr file.
+type workingDirMetadata struct {
+	Name       string    `json:"name"`
+	InputFile  string    `json:"input_file"`
+	DeployedAt time.Time `json:"deployed_at"`
+	// ExpiresAt is when the cluster expires, nil if it never does.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Devcontainers maps the ids of the devcontainers that expire to their expiry.
+	Devcontainers map[string]time.Time `json:"devcontainers,omitempty"`
+	// Config is the configuration deployed, with defaults applied and variables resolved, used to remove expired devcontainers.
+	Config json.RawMessage `json:"config"`
+}
+
+// newWorkingDirMetadata computes the expiries of a configuration deployed at the given time.
+func newWorkingDirMetadata(inputFile string, root *schema.DenvclustrRoot, deployedAt time.Time) (*workingDirMetadata, error) {
+	absInputFile, err := filepath.Abs(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve input file path: %w", err)
+	}
+	config, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode deployed configuration: %w", err)
+	}
+
+	metadata := &workingDirMetadata{
+		Name:       string(root.Name),
+		InputFile:  absInputFile,
+		DeployedAt: deployedAt.UTC(),
+		Config:     config,
+	}
+
+	expiresAt, err := root.Expiry(deployedAt)
+	if err != nil {
+		return nil, err
+	}
+	if !expiresAt.IsZero() {
+		expiresAt = expiresAt.UTC()
+		metadata.ExpiresAt = &expiresAt
+	}
+	for _, devcontainer := range schema.ExpandReplicas(root.Devcontainers) {
+		expiresAt, err := devcontainer.Expiry(deployedAt)
+		if err != nil {
+			return nil, fmt.Errorf("devcontainer %q: %w", devcontainer.Id, err)
+		}
+		if expiresAt.IsZero() {
+			continue
+		}
+		if metadata.Devcontainers == nil {
+			metadata.Devcontainers = make(map[string]time.Time)
+		}
+		metadata.Devcontainers[string(devcontainer.Id)] = expiresAt.UTC()
+	}
+	return metadata, nil
+}
+
+// expiredDevcontainers returns the ids of the devcontainers expired at the given time, sorted.
+func (m *workingDirMetadata) expiredDevcontainers(now time.Time) []string {
+	var expired []string
+	for id, expiresAt := range m.Devcontainers {
+		if !expiresAt.After(now) {
+			expired = append(expired, id)
+		}
+	}
+	slices.Sort(expired)
+	return expired
+}
+
+// root returns the configuration deployed from the working directory.
+func (m *workingDirMetadata) root() (*schema.DenvclustrRoot, error) {
+	var root schema.DenvclustrRoot
+	if err := json.Unmarshal(m.Config, &root); err != nil {
+		return nil, fmt.Errorf("failed to decode deployed configuration: %w", err)
+	}
+	return &root, nil
+}
+
+func readMetadata(workingDir string) (*workingDirMetadata, error) {
+	data, err := os.ReadFile(filepath.Join(workingDir, metadataFileName))
+	if err != nil {
+		return nil, err
+	}
+	var metadata workingDirMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", metadataFileName, err)
+	}
+	return &metadata, nil
+}
+
+func writeMetadata(workingDir string, metadata *workingDirMetadata) error {
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(workingDir, metadataFileName), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return nil
+}
+
+// stateDir returns the directory of the files denvclustr keeps across working directories,
+// such as the list of known working directories and the audit log of 'denvclustr reap'.
+func stateDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the user configuration directory: %w", err)
+	}
+	return filepath.Join(configDir, "denvclustr"), nil
+}
+
+// knownWorkingDirsPath returns the file listing the working directories deployed from, one per line.
+func knownWorkingDirsPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "working-dirs"), nil
+}
+
+// readKnownWorkingDirs returns the working directories deployed from, in the order they were first deployed.
+func readKnownWorkingDirs() ([]string, error) {
+	path, err := knownWorkingDirsPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known working directories: %w", err)
+	}
+	defer file.Close()
+
+	var dirs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if dir := strings.TrimSpace(scanner.Text()); dir != "" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read known working directories: %w", err)
+	}
+	return dirs, nil
+}
+
+func writeKnownWorkingDirs(dirs []string) error {
+	path, err := knownWorkingDirsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	var content strings.Builder
+	for _, dir := range dirs {
+		content.WriteString(dir + "\n")
+	}
+	if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write known working directories: %w", err)
+	}
+	return nil
+}
+
+// registerWorkingDir adds a working directory to the known working directories scanned by 'denvclustr reap'.
+func registerWorkingDir(workingDir string) error {
+	dirs, err := readKnownWorkingDirs()
+	if err != nil {
+		return err
+	}
+	if slices.Contains(dirs, workingDir) {
+		return nil
+	}
+	return writeKnownWorkingDirs(append(dirs, workingDir))
+}
+
+// unregisterWorkingDir removes a working directory whose resources were destroyed from the known working directories.
+func unregisterWorkingDir(workingDir string) error {
+	dirs, err := readKnownWorkingDirs()
+	if err != nil {
+		return err
+	}
+	index := slices.Index(dirs, workingDir)
+	if index < 0 {
+		return nil
+	}
+	return writeKnownWorkingDirs(slices.Delete(dirs, index, index+1))
+}
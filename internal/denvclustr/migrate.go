@@ -0,0 +1,72 @@
+package denvclustr
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/tropicaltux/denvclustr/pkg/schema"
+)
+
+// migrateFile upgrades a denvclustr file to the current file format version.
+// The file is rewritten in place unless showDiff is set, in which case the changes are only printed.
+// JSONC files lose their comments when rewritten, so they are only rewritten in place when force is set.
+func migrateFile(inputFile string, showDiff, force bool) error {
+	slog.Info("Migrating denvclustr file", "input", inputFile, "diff", showDiff, "force", force)
+
+	data, err := readInputFile(inputFile)
+	if err != nil {
+		return err
+	}
+
+	format := schema.DetectFormat(inputFile, data)
+	result, err := schema.Migrate(data, format)
+	if err != nil {
+		return fmt.Errorf("failed to migrate denvclustr file: %w", err)
+	}
+
+	if !result.Changed {
+		fmt.Printf("%s is already at version %s, nothing to migrate.\n", inputFile, result.ToVersion)
+		return nil
+	}
+
+	if showDiff {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(data)),
+			B:        difflib.SplitLines(string(result.Data)),
+			FromFile: inputFile,
+			ToFile:   inputFile,
+			Context:  3,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to compute diff: %w", err)
+		}
+		fmt.Print(diff)
+		return nil
+	}
+
+	if format == schema.FormatJSONC {
+		if !force {
+			return fmt.Errorf("%s is a JSONC file and its comments would be lost: review the changes with --diff, or rewrite it anyway with --force", inputFile)
+		}
+		fmt.Println("⚠️  Comments and trailing commas are not preserved when migrating JSONC files.")
+	}
+
+	info, err := os.Stat(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to stat input file: %w", err)
+	}
+	if err := os.WriteFile(inputFile, result.Data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write migrated file: %w", err)
+	}
+
+	slog.Info("Successfully migrated denvclustr file", "input", inputFile, "from", result.FromVersion, "to", result.ToVersion)
+	if result.FromVersion == result.ToVersion {
+		fmt.Printf("Recorded version %s in %s.\n", result.ToVersion, inputFile)
+	} else {
+		fmt.Printf("Migrated %s from version %s to version %s.\n", inputFile, result.FromVersion, result.ToVersion)
+	}
+	return nil
+}
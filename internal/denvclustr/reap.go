@@ -0,0 +1,261 @@
+package denvclustr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/tropicaltux/denvclustr/pkg/dc2tf"
+	"github.com/tropicaltux/denvclustr/pkg/schema"
+)
+
+// Actions recorded in the audit log of 'denvclustr reap'.
+const (
+	reapActionDestroy             = "destroy"
+	reapActionRemoveDevcontainers = "remove-devcontainers"
+	reapActionForget              = "forget"
+)
+
+// reapAuditEntry is a line of the audit log of 'denvclustr reap', in JSON.
+type reapAuditEntry struct {
+	Time          time.Time  `json:"time"`
+	User          string     `json:"user,omitempty"`
+	Host          string     `json:"host,omitempty"`
+	WorkingDir    string     `json:"working_dir"`
+	Cluster       string     `json:"cluster,omitempty"`
+	Action        string     `json:"action"`
+	Devcontainers []string   `json:"devcontainers,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Succeeded     bool       `json:"succeeded"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// reapAuditLog appends the actions of 'denvclustr reap' to a file.
+type reapAuditLog struct {
+	path string
+	user string
+	host string
+}
+
+func newReapAuditLog(path string) (*reapAuditLog, error) {
+	if path == "" {
+		dir, err := stateDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "reap.log")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	log := &reapAuditLog{path: path}
+	if current, err := user.Current(); err == nil {
+		log.user = current.Username
+	}
+	log.host, _ = os.Hostname()
+	return log, nil
+}
+
+// record appends an entry to the audit log, with the outcome of the action.
+func (l *reapAuditLog) record(entry reapAuditEntry, actionErr error) error {
+	entry.Time = time.Now().UTC()
+	entry.User = l.user
+	entry.Host = l.host
+	entry.Succeeded = actionErr == nil
+	if actionErr != nil {
+		entry.Error = actionErr.Error()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// reapWorkingDirs destroys the expired clusters deployed from the given working directories, or from the
+// known working directories if none is given, and removes their expired devcontainers. Nothing is confirmed:
+// every action is recorded in the audit log instead. With planOnly, the actions are only shown.
+func reapWorkingDirs(workDirPaths []string, auditLogPath string, planOnly bool) error {
+	if !planOnly {
+		if err := checkTerraformInstalled(); err != nil {
+			return fmt.Errorf("terraform is required for reap: %w", err)
+		}
+	}
+
+	var workingDirs []string
+	for _, path := range workDirPaths {
+		workingDir, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve working directory path: %w", err)
+		}
+		workingDirs = append(workingDirs, workingDir)
+	}
+	if len(workingDirs) == 0 {
+		known, err := readKnownWorkingDirs()
+		if err != nil {
+			return err
+		}
+		workingDirs = known
+	}
+	if len(workingDirs) == 0 {
+		fmt.Println("No known working directories. Nothing to reap.")
+		return nil
+	}
+
+	var audit *reapAuditLog
+	if planOnly {
+		fmt.Println("Showing what would be reaped, nothing is changed.")
+	} else {
+		var err error
+		if audit, err = newReapAuditLog(auditLogPath); err != nil {
+			return err
+		}
+		slog.Info("Recording reaped working directories", "audit-log", audit.path)
+	}
+
+	now := time.Now()
+	failed := 0
+	for _, workingDir := range workingDirs {
+		if err := reapWorkingDir(workingDir, audit, now); err != nil {
+			slog.Error("Failed to reap working directory", "path", workingDir, "error", err)
+			fmt.Printf("❌ %s: %s\n", workingDir, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to reap %d of %d working directories", failed, len(workingDirs))
+	}
+	return nil
+}
+
+// reapWorkingDir destroys the cluster deployed from a working directory if it expired, or if all of its
+// devcontainers did, and otherwise removes its expired devcontainers. Without an audit log, it only
+// shows what would be done.
+func reapWorkingDir(workingDir string, audit *reapAuditLog, now time.Time) error {
+	if _, err := os.Stat(workingDir); errors.Is(err, os.ErrNotExist) {
+		// Its state is gone with it, so nothing can be destroyed from it anymore
+		if audit == nil {
+			fmt.Printf("🗑️  %s: working directory not found, it would be forgotten\n", workingDir)
+			return nil
+		}
+		fmt.Printf("🗑️  %s: working directory not found, forgetting it\n", workingDir)
+		err := unregisterWorkingDir(workingDir)
+		return errors.Join(err, audit.record(reapAuditEntry{WorkingDir: workingDir, Action: reapActionForget}, err))
+	}
+
+	metadata, err := readMetadata(workingDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s not found, deploy from the working directory again to record its expiry", metadataFileName)
+	}
+	if err != nil {
+		return err
+	}
+
+	root, err := metadata.root()
+	if err != nil {
+		return err
+	}
+	expired := metadata.expiredDevcontainers(now)
+	devcontainers := schema.ExpandReplicas(root.Devcontainers)
+
+	entry := reapAuditEntry{WorkingDir: workingDir, Cluster: metadata.Name, ExpiresAt: metadata.ExpiresAt}
+	switch {
+	case metadata.ExpiresAt != nil && !metadata.ExpiresAt.After(now):
+		fmt.Printf("⌛ Cluster %s in %s expired on %s, destroying it\n", metadata.Name, workingDir, metadata.ExpiresAt.Local().Format(scheduleTimeFormat))
+		entry.Action = reapActionDestroy
+	case len(devcontainers) > 0 && len(expired) == len(devcontainers):
+		fmt.Printf("⌛ All devcontainers of cluster %s in %s expired, destroying it\n", metadata.Name, workingDir)
+		entry.Action = reapActionDestroy
+		entry.Devcontainers = expired
+	case len(expired) > 0:
+		fmt.Printf("⌛ Devcontainers %s of cluster %s in %s expired, removing them\n", strings.Join(expired, ", "), metadata.Name, workingDir)
+		entry.Action = reapActionRemoveDevcontainers
+		entry.Devcontainers = expired
+	default:
+		slog.Info("Nothing expired", "path", workingDir, "cluster", metadata.Name)
+		return nil
+	}
+	if audit == nil {
+		return nil
+	}
+
+	if entry.Action == reapActionDestroy {
+		err = destroyExpiredCluster(workingDir)
+	} else {
+		err = removeDevcontainers(workingDir, metadata, root, devcontainers, expired)
+	}
+	return errors.Join(err, audit.record(entry, err))
+}
+
+// destroyExpiredCluster destroys the resources of a working directory without confirmation and forgets it.
+func destroyExpiredCluster(workingDir string) error {
+	if _, err := destroyWorkingDir(workingDir, false); err != nil {
+		return err
+	}
+	return unregisterWorkingDir(workingDir)
+}
+
+// removeDevcontainers applies the configuration deployed from a working directory without the expired
+// devcontainers, and records it in the metadata so they are not deployed again by the next reap.
+func removeDevcontainers(workingDir string, metadata *workingDirMetadata, root *schema.DenvclustrRoot, devcontainers []*schema.Devcontainer, expired []string) error {
+	root.Devcontainers = slices.DeleteFunc(devcontainers, func(devcontainer *schema.Devcontainer) bool {
+		return slices.Contains(expired, string(devcontainer.Id))
+	})
+
+	hclFile, err := dc2tf.Convert(root)
+	if err != nil {
+		return fmt.Errorf("failed to convert to Terraform: %w", err)
+	}
+	tfFilePath := filepath.Join(workingDir, "main.tf")
+	if err := os.WriteFile(tfFilePath, hclFile.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write terraform file: %w", err)
+	}
+
+	tf, err := tfexec.NewTerraform(workingDir, "terraform")
+	if err != nil {
+		return fmt.Errorf("failed to initialize terraform: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	fmt.Println("Initializing Terraform...")
+	if err := tf.Init(ctx); err != nil {
+		return fmt.Errorf("failed to run terraform init: %w", err)
+	}
+
+	fmt.Println("Removing expired devcontainers...")
+	if err := tf.Apply(ctx); err != nil {
+		return fmt.Errorf("failed to run terraform apply: %w", err)
+	}
+
+	config, err := json.Marshal(root)
+	if err != nil {
+		return fmt.Errorf("failed to encode deployed configuration: %w", err)
+	}
+	metadata.Config = config
+	for _, id := range expired {
+		delete(metadata.Devcontainers, id)
+	}
+	return writeMetadata(workingDir, metadata)
+}
@@ -0,0 +1,42 @@
+package denvclustr
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/tropicaltux/denvclustr/pkg/schema"
+)
+
+// printEffectiveConfig prints the configuration actually deployed from a denvclustr file,
+// in the format of the file, once included files and the overlay are merged, variables are
+// resolved and defaults are applied. Defaults are not applied when raw is set.
+// The output is itself a valid denvclustr file.
+func printEffectiveConfig(inputFile string, raw bool) error {
+	slog.Info("Printing effective configuration", "input", inputFile, "environment", environment, "raw", raw)
+
+	data, err := readInputFile(inputFile)
+	if err != nil {
+		return err
+	}
+
+	options, err := parseOptions(inputFile, data)
+	if err != nil {
+		return err
+	}
+	if raw {
+		options = append(options, schema.WithRaw())
+	}
+
+	root, err := schema.Parse(data, options...)
+	if err != nil {
+		return fmt.Errorf("failed to parse denvclustr file: %w", err)
+	}
+
+	config, err := schema.MarshalEffective(root, schema.DetectFormat(inputFile, data))
+	if err != nil {
+		return fmt.Errorf("failed to encode effective configuration: %w", err)
+	}
+
+	fmt.Print(string(config))
+	return nil
+}
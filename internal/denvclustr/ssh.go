@@ -0,0 +1,53 @@
+package denvclustr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tropicaltux/denvclustr/pkg/schema"
+)
+
+// sshCommand adapts the SSH command of a devcontainer, as output by the module, to the access mode of its node:
+// connections to nodes behind a bastion jump through it with ProxyJump, and connections to nodes reached
+// through SSM sessions are tunneled with ProxyCommand, which needs the instance id of the node.
+func sshCommand(command string, node *schema.Node, instanceId, region string) string {
+	args, ok := strings.CutPrefix(command, "ssh ")
+	if node == nil || !ok {
+		return command
+	}
+
+	switch node.RemoteAccess.AccessMode() {
+	case schema.AccessModeBastion:
+		if node.RemoteAccess.Bastion == nil {
+			return command
+		}
+		return fmt.Sprintf("ssh -J %s %s", bastionAddress(node.RemoteAccess.Bastion), args)
+	case schema.AccessModeSSMSession:
+		if instanceId == "" {
+			instanceId = "INSTANCE_ID"
+		}
+		proxy := fmt.Sprintf("aws ssm start-session --target %s --document-name AWS-StartSSHSession --parameters portNumber=%%p", instanceId)
+		if region != "" {
+			proxy += " --region " + region
+		}
+		return fmt.Sprintf("ssh -o ProxyCommand='%s' %s", proxy, args)
+	}
+	return command
+}
+
+// bastionAddress returns the jump host in the form expected by ssh -J: "[user@]host[:port]",
+// or an ssh:// URI for IPv6 addresses with a port.
+func bastionAddress(bastion *schema.NodeBastion) string {
+	address := string(bastion.Host)
+	if bastion.User != "" {
+		address = string(bastion.User) + "@" + address
+	}
+	if bastion.Port == nil {
+		return address
+	}
+	if strings.Contains(string(bastion.Host), ":") {
+		address = strings.Replace(address, string(bastion.Host), "["+string(bastion.Host)+"]", 1)
+		return fmt.Sprintf("ssh://%s:%d", address, *bastion.Port)
+	}
+	return fmt.Sprintf("%s:%d", address, *bastion.Port)
+}
@@ -0,0 +1,255 @@
+package denvclustr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/tropicaltux/denvclustr/pkg/schema"
+)
+
+// Enum of supported validation report formats.
+const (
+	reportFormatHuman = "human"
+	reportFormatJSON  = "json"
+	reportFormatSARIF = "sarif"
+)
+
+const (
+	sarifSchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion   = "2.1.0"
+	informationURI = "https://github.com/tropicaltux/denvclustr"
+)
+
+// validateFile validates a denvclustr file and prints a report in the requested format.
+// It returns an error if the file contains at least one problem of error severity.
+func validateFile(inputFile, reportFormat string) error {
+	// The format is checked first, so a typo is not hidden behind the problems of the file
+	switch reportFormat {
+	case reportFormatHuman, reportFormatJSON, reportFormatSARIF:
+	default:
+		return fmt.Errorf("unsupported report format %q (expected %s, %s or %s)", reportFormat, reportFormatHuman, reportFormatJSON, reportFormatSARIF)
+	}
+
+	slog.Info("Validating denvclustr file", "input", inputFile, "format", reportFormat)
+
+	data, err := readInputFile(inputFile)
+	if err != nil {
+		return err
+	}
+
+	options, err := parseOptions(inputFile, data)
+	if err != nil {
+		return err
+	}
+
+	options = append(options, schema.WithPublicKeyCheck())
+	root, errs, err := schema.ParseAndValidate(data, options...)
+	if err != nil {
+		return fmt.Errorf("failed to validate denvclustr file: %w", err)
+	}
+
+	switch reportFormat {
+	case reportFormatHuman:
+		writeHumanReport(os.Stdout, inputFile, errs)
+		// The model is only returned for a valid file
+		if root != nil {
+			printPublicKeys(os.Stdout, root)
+		}
+	case reportFormatJSON:
+		if err := writeJSONReport(os.Stdout, inputFile, errs); err != nil {
+			return err
+		}
+	case reportFormatSARIF:
+		if err := writeSARIFReport(os.Stdout, inputFile, errs); err != nil {
+			return err
+		}
+	}
+
+	if errs.HasErrors() {
+		errorCount, _ := countBySeverity(errs)
+		return fmt.Errorf("%s is invalid: %d error(s) found", inputFile, errorCount)
+	}
+	return nil
+}
+
+// errorFile returns the file an error is located in: the overlay it comes from, or the input file.
+func errorFile(inputFile string, err *schema.ValidationError) string {
+	if err.File != "" {
+		return err.File
+	}
+	return inputFile
+}
+
+func countBySeverity(errs schema.ValidationErrors) (int, int) {
+	var errorCount, warningCount int
+	for _, err := range errs {
+		switch err.Severity {
+		case schema.SeverityError:
+			errorCount++
+		case schema.SeverityWarning:
+			warningCount++
+		}
+	}
+	return errorCount, warningCount
+}
+
+// writeHumanReport prints one line per problem in the file:line:column format understood by most editors.
+func writeHumanReport(w io.Writer, inputFile string, errs schema.ValidationErrors) {
+	for _, err := range errs {
+		location := errorFile(inputFile, err)
+		if err.Line > 0 {
+			location = fmt.Sprintf("%s:%d:%d", location, err.Line, err.Column)
+		}
+		if err.Pointer != "" {
+			fmt.Fprintf(w, "%s: %s: %s [%s] (at %s)\n", location, err.Severity, err.Message, err.Code, err.Pointer)
+		} else {
+			fmt.Fprintf(w, "%s: %s: %s [%s]\n", location, err.Severity, err.Message, err.Code)
+		}
+	}
+
+	errorCount, warningCount := countBySeverity(errs)
+	if errorCount == 0 {
+		fmt.Fprintf(w, "✅ %s is valid (%d warning(s))\n", inputFile, warningCount)
+	} else {
+		fmt.Fprintf(w, "❌ %s is invalid: %d error(s), %d warning(s)\n", inputFile, errorCount, warningCount)
+	}
+}
+
+type jsonReport struct {
+	File   string                  `json:"file"`
+	Valid  bool                    `json:"valid"`
+	Errors schema.ValidationErrors `json:"errors"`
+}
+
+func writeJSONReport(w io.Writer, inputFile string, errs schema.ValidationErrors) error {
+	if errs == nil {
+		errs = schema.ValidationErrors{}
+	}
+
+	report := jsonReport{File: inputFile, Valid: !errs.HasErrors(), Errors: errs}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode JSON report: %w", err)
+	}
+	return nil
+}
+
+// SARIF 2.1.0 types, limited to the properties used by the report.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func writeSARIFReport(w io.Writer, inputFile string, errs schema.ValidationErrors) error {
+	ruleIds := make(map[string]struct{})
+	results := []sarifResult{}
+	for _, err := range errs {
+		ruleIds[err.Code] = struct{}{}
+
+		uri := filepath.ToSlash(errorFile(inputFile, err))
+		location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: uri}}}
+		if err.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: err.Line, StartColumn: err.Column}
+		}
+
+		result := sarifResult{
+			RuleID:    err.Code,
+			Level:     sarifLevel(err.Severity),
+			Message:   sarifMessage{Text: err.Message},
+			Locations: []sarifLocation{location},
+		}
+		if err.Pointer != "" {
+			result.Properties = map[string]string{"pointer": err.Pointer}
+		}
+		results = append(results, result)
+	}
+
+	rules := []sarifRule{}
+	for id := range ruleIds {
+		rules = append(rules, sarifRule{ID: id})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	report := sarifLog{
+		Schema:  sarifSchemaURI,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "denvclustr", InformationURI: informationURI, Rules: rules}},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode SARIF report: %w", err)
+	}
+	return nil
+}
+
+func sarifLevel(severity schema.Severity) string {
+	switch severity {
+	case schema.SeverityError:
+		return "error"
+	case schema.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
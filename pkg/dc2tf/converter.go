@@ -31,6 +31,9 @@ func (c *converter) toTerraform() (*hclwrite.File, error) {
 	if err := c.addModules(root); err != nil {
 		return nil, err
 	}
+	if err := c.addSchedules(root); err != nil {
+		return nil, err
+	}
 	if err := c.addOutputs(root); err != nil {
 		return nil, err
 	}
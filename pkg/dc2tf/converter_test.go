@@ -51,6 +51,29 @@ func TestConvert(t *testing.T) {
 	emptyDevcontainers, err := testdataFS.ReadFile("testdata/empty_devcontainers.tf")
 	require.NoError(t, err)
 
+	replicas, err := testdataFS.ReadFile("testdata/replicas.tf")
+	require.NoError(t, err)
+	pinnedRevisions, err := testdataFS.ReadFile("testdata/pinned_revisions.tf")
+	require.NoError(t, err)
+	image, err := testdataFS.ReadFile("testdata/image.tf")
+	require.NoError(t, err)
+	additionalRepositories, err := testdataFS.ReadFile("testdata/additional_repositories.tf")
+	require.NoError(t, err)
+	environment, err := testdataFS.ReadFile("testdata/environment.tf")
+	require.NoError(t, err)
+	tags, err := testdataFS.ReadFile("testdata/tags.tf")
+	require.NoError(t, err)
+	nodeProperties, err := testdataFS.ReadFile("testdata/node_properties.tf")
+	require.NoError(t, err)
+	network, err := testdataFS.ReadFile("testdata/network.tf")
+	require.NoError(t, err)
+	accessModes, err := testdataFS.ReadFile("testdata/access_modes.tf")
+	require.NoError(t, err)
+	spot, err := testdataFS.ReadFile("testdata/spot.tf")
+	require.NoError(t, err)
+	schedule, err := testdataFS.ReadFile("testdata/schedule.tf")
+	require.NoError(t, err)
+
 	// Parse expected HCL files
 	parser := hclparse.NewParser()
 	expectedMinimal, diags1 := parser.ParseHCL(minimal, "expected_minimal.tf")
@@ -66,6 +89,29 @@ func TestConvert(t *testing.T) {
 	expectedEmptyDevcontainers, diags6 := parser.ParseHCL(emptyDevcontainers, "expected_empty_devcontainers.tf")
 	require.False(t, diags6.HasErrors(), "failed parsing expected empty devcontainers: %v", diags6)
 
+	expectedReplicas, diags7 := parser.ParseHCL(replicas, "expected_replicas.tf")
+	require.False(t, diags7.HasErrors(), "failed parsing expected replicas: %v", diags7)
+	expectedPinnedRevisions, diags8 := parser.ParseHCL(pinnedRevisions, "expected_pinned_revisions.tf")
+	require.False(t, diags8.HasErrors(), "failed parsing expected pinned revisions: %v", diags8)
+	expectedImage, diags9 := parser.ParseHCL(image, "expected_image.tf")
+	require.False(t, diags9.HasErrors(), "failed parsing expected image: %v", diags9)
+	expectedAdditionalRepositories, diags10 := parser.ParseHCL(additionalRepositories, "expected_additional_repositories.tf")
+	require.False(t, diags10.HasErrors(), "failed parsing expected additional repositories: %v", diags10)
+	expectedEnvironment, diags11 := parser.ParseHCL(environment, "expected_environment.tf")
+	require.False(t, diags11.HasErrors(), "failed parsing expected environment: %v", diags11)
+	expectedTags, diags12 := parser.ParseHCL(tags, "expected_tags.tf")
+	require.False(t, diags12.HasErrors(), "failed parsing expected tags: %v", diags12)
+	expectedNodeProperties, diags13 := parser.ParseHCL(nodeProperties, "expected_node_properties.tf")
+	require.False(t, diags13.HasErrors(), "failed parsing expected node properties: %v", diags13)
+	expectedNetwork, diags14 := parser.ParseHCL(network, "expected_network.tf")
+	require.False(t, diags14.HasErrors(), "failed parsing expected network: %v", diags14)
+	expectedAccessModes, diags15 := parser.ParseHCL(accessModes, "expected_access_modes.tf")
+	require.False(t, diags15.HasErrors(), "failed parsing expected access modes: %v", diags15)
+	expectedSpot, diags16 := parser.ParseHCL(spot, "expected_spot.tf")
+	require.False(t, diags16.HasErrors(), "failed parsing expected spot: %v", diags16)
+	expectedSchedule, diags17 := parser.ParseHCL(schedule, "expected_schedule.tf")
+	require.False(t, diags17.HasErrors(), "failed parsing expected schedule: %v", diags17)
+
 	cases := []struct {
 		name     string
 		spec     *schema.DenvclustrRoot
@@ -240,6 +286,363 @@ func TestConvert(t *testing.T) {
 			}},
 			Devcontainers: []*schema.Devcontainer{},
 		}, expectedEmptyDevcontainers},
+		{"replicas", &schema.DenvclustrRoot{
+			Name: schema.TrimmedString("test-cluster"),
+			Infrastructure: []*schema.Infrastructure{{
+				Id:       schema.TrimmedString("infrastructure1"),
+				Provider: schema.ProviderAws,
+				Kind:     schema.KindVm,
+				Region:   schema.TrimmedString("us-west-2"),
+			}},
+			Nodes: []*schema.Node{{
+				Id:               schema.TrimmedString("node1"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties:       schema.NodeProperties{InstanceType: schema.TrimmedString("t3.xlarge")},
+				RemoteAccess:     schema.NodeRemoteAccess{PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub")},
+			}},
+			Devcontainers: []*schema.Devcontainer{{
+				Id:       schema.TrimmedString("api-dev"),
+				NodeId:   schema.TrimmedString("node1"),
+				Replicas: func() *int { replicas := 2; return &replicas }(),
+				Source:   &schema.DevcontainerSource{URL: schema.TrimmedString("https://github.com/example/api")},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{
+					Ssh: &schema.DevcontainerSSH{Port: func() *int { port := 2200; return &port }()},
+				},
+			}, {
+				Id:     schema.TrimmedString("docs"),
+				NodeId: schema.TrimmedString("node1"),
+				Source: &schema.DevcontainerSource{
+					URL:    schema.TrimmedString("https://github.com/example/docs"),
+					Branch: schema.TrimmedString("main"),
+				},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}},
+		}, expectedReplicas},
+		{"pinned revisions", &schema.DenvclustrRoot{
+			Name: schema.TrimmedString("pinned-cluster"),
+			Infrastructure: []*schema.Infrastructure{{
+				Id:       schema.TrimmedString("infrastructure1"),
+				Provider: schema.ProviderAws,
+				Kind:     schema.KindVm,
+				Region:   schema.TrimmedString("us-west-2"),
+			}},
+			Nodes: []*schema.Node{{
+				Id:               schema.TrimmedString("node1"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties:       schema.NodeProperties{InstanceType: schema.TrimmedString("t3.micro")},
+				RemoteAccess:     schema.NodeRemoteAccess{PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub")},
+			}},
+			Devcontainers: []*schema.Devcontainer{{
+				Id:     schema.TrimmedString("release"),
+				NodeId: schema.TrimmedString("node1"),
+				Source: &schema.DevcontainerSource{
+					URL: schema.TrimmedString("https://github.com/example/repo"),
+					Tag: schema.TrimmedString("v1.4.2"),
+				},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}, {
+				Id:     schema.TrimmedString("bisect"),
+				NodeId: schema.TrimmedString("node1"),
+				Source: &schema.DevcontainerSource{
+					URL:    schema.TrimmedString("https://github.com/example/repo"),
+					Commit: schema.TrimmedString("9fceb02d0ae598e95dc970b74767f19372d61af8"),
+				},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}},
+		}, expectedPinnedRevisions},
+		{"prebuilt images", &schema.DenvclustrRoot{
+			Name: schema.TrimmedString("image-cluster"),
+			Infrastructure: []*schema.Infrastructure{{
+				Id:       schema.TrimmedString("infrastructure1"),
+				Provider: schema.ProviderAws,
+				Kind:     schema.KindVm,
+				Region:   schema.TrimmedString("us-west-2"),
+			}},
+			Nodes: []*schema.Node{{
+				Id:               schema.TrimmedString("node1"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties:       schema.NodeProperties{InstanceType: schema.TrimmedString("t3.micro")},
+				RemoteAccess:     schema.NodeRemoteAccess{PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub")},
+			}},
+			Devcontainers: []*schema.Devcontainer{{
+				Id:     schema.TrimmedString("api"),
+				NodeId: schema.TrimmedString("node1"),
+				Image: &schema.DevcontainerImage{
+					Reference: schema.TrimmedString("ghcr.io/example/api-dev:1.4.2"),
+					Credentials: &schema.DevcontainerImageCredentials{
+						Reference: schema.TrimmedString("registry-credentials"),
+						Source:    schema.SshKeySourceSecretsManager,
+					},
+				},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}, {
+				Id:           schema.TrimmedString("tools"),
+				NodeId:       schema.TrimmedString("node1"),
+				Image:        &schema.DevcontainerImage{Reference: schema.TrimmedString("ubuntu:24.04")},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}},
+		}, expectedImage},
+		{"additional repositories", &schema.DenvclustrRoot{
+			Name: schema.TrimmedString("workspace-cluster"),
+			Infrastructure: []*schema.Infrastructure{{
+				Id:       schema.TrimmedString("infrastructure1"),
+				Provider: schema.ProviderAws,
+				Kind:     schema.KindVm,
+				Region:   schema.TrimmedString("us-west-2"),
+			}},
+			Nodes: []*schema.Node{{
+				Id:               schema.TrimmedString("node1"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties:       schema.NodeProperties{InstanceType: schema.TrimmedString("t3.micro")},
+				RemoteAccess:     schema.NodeRemoteAccess{PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub")},
+			}},
+			Devcontainers: []*schema.Devcontainer{{
+				Id:     schema.TrimmedString("platform"),
+				NodeId: schema.TrimmedString("node1"),
+				Source: &schema.DevcontainerSource{URL: schema.TrimmedString("https://github.com/example/platform")},
+				AdditionalRepositories: []*schema.DevcontainerRepository{{
+					URL:  schema.TrimmedString("https://github.com/example/billing"),
+					Path: schema.TrimmedString("services/billing"),
+					Tag:  schema.TrimmedString("v2.0.1"),
+				}, {
+					URL:    schema.TrimmedString("git@github.com:example/identity.git"),
+					Path:   schema.TrimmedString("services/identity"),
+					Branch: schema.TrimmedString("main"),
+					SshKey: &schema.DevcontainerSourceSSHKey{
+						Reference: schema.TrimmedString("/keys/identity"),
+						Source:    schema.SshKeySourceSsmParameterStore,
+					},
+				}},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}},
+		}, expectedAdditionalRepositories},
+		{"environment and secrets", &schema.DenvclustrRoot{
+			Name: schema.TrimmedString("api-cluster"),
+			Infrastructure: []*schema.Infrastructure{{
+				Id:       schema.TrimmedString("infrastructure1"),
+				Provider: schema.ProviderAws,
+				Kind:     schema.KindVm,
+				Region:   schema.TrimmedString("us-west-2"),
+			}},
+			Nodes: []*schema.Node{{
+				Id:               schema.TrimmedString("node1"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties:       schema.NodeProperties{InstanceType: schema.TrimmedString("t3.micro")},
+				RemoteAccess:     schema.NodeRemoteAccess{PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub")},
+			}},
+			Devcontainers: []*schema.Devcontainer{{
+				Id:     schema.TrimmedString("api"),
+				NodeId: schema.TrimmedString("node1"),
+				Source: &schema.DevcontainerSource{URL: schema.TrimmedString("https://github.com/example/api")},
+				Env: map[string]schema.TrimmedString{
+					"API_URL":   "https://api.staging.example.com",
+					"LOG_LEVEL": "debug",
+				},
+				Secrets: []*schema.DevcontainerSecret{{
+					Name:      schema.TrimmedString("API_TOKEN"),
+					Source:    schema.SshKeySourceSecretsManager,
+					Reference: schema.TrimmedString("staging/api-token"),
+				}, {
+					Name:      schema.TrimmedString("DATABASE_PASSWORD"),
+					Source:    schema.SshKeySourceSsmParameterStore,
+					Reference: schema.TrimmedString("/staging/database/password"),
+				}},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}},
+		}, expectedEnvironment},
+		{"tags", &schema.DenvclustrRoot{
+			Name: schema.TrimmedString("api-cluster"),
+			Tags: map[string]schema.TrimmedString{"cost-center": "platform", "Owner": "platform-team"},
+			Infrastructure: []*schema.Infrastructure{{
+				Id:       schema.TrimmedString("infrastructure1"),
+				Provider: schema.ProviderAws,
+				Kind:     schema.KindVm,
+				Region:   schema.TrimmedString("us-west-2"),
+				Tags:     map[string]schema.TrimmedString{"Environment": "staging"},
+			}},
+			Nodes: []*schema.Node{{
+				Id:               schema.TrimmedString("node1"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties:       schema.NodeProperties{InstanceType: schema.TrimmedString("t3.micro")},
+				RemoteAccess:     schema.NodeRemoteAccess{PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub")},
+				Tags:             map[string]schema.TrimmedString{"Owner": "api-team"},
+			}},
+			Devcontainers: []*schema.Devcontainer{{
+				Id:           schema.TrimmedString("api"),
+				NodeId:       schema.TrimmedString("node1"),
+				Source:       &schema.DevcontainerSource{URL: schema.TrimmedString("https://github.com/example/api")},
+				Tags:         map[string]schema.TrimmedString{"Project": "api"},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}},
+		}, expectedTags},
+		{"node properties", &schema.DenvclustrRoot{
+			Name: schema.TrimmedString("graviton-cluster"),
+			Infrastructure: []*schema.Infrastructure{{
+				Id:       schema.TrimmedString("infrastructure1"),
+				Provider: schema.ProviderAws,
+				Kind:     schema.KindVm,
+				Region:   schema.TrimmedString("us-west-2"),
+			}},
+			Nodes: []*schema.Node{{
+				Id:               schema.TrimmedString("node1"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties: schema.NodeProperties{
+					InstanceType: schema.TrimmedString("m7g.large"),
+					Architecture: schema.ArchitectureArm64,
+					AMI:          schema.TrimmedString("ami-0123456789abcdef0"),
+					RootVolume: &schema.NodeRootVolume{
+						Size:      func() *int { size := 100; return &size }(),
+						Encrypted: func() *bool { encrypted := true; return &encrypted }(),
+						KmsKeyId:  schema.TrimmedString("alias/dev-volumes"),
+					},
+					RequireIMDSv2: func() *bool { required := true; return &required }(),
+				},
+				RemoteAccess: schema.NodeRemoteAccess{PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub")},
+			}},
+			Devcontainers: []*schema.Devcontainer{{
+				Id:           schema.TrimmedString("api"),
+				NodeId:       schema.TrimmedString("node1"),
+				Source:       &schema.DevcontainerSource{URL: schema.TrimmedString("https://github.com/example/api")},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}},
+		}, expectedNodeProperties},
+		{"network", &schema.DenvclustrRoot{
+			Name: schema.TrimmedString("corporate-cluster"),
+			Infrastructure: []*schema.Infrastructure{{
+				Id:       schema.TrimmedString("infrastructure1"),
+				Provider: schema.ProviderAws,
+				Kind:     schema.KindVm,
+				Region:   schema.TrimmedString("us-west-2"),
+			}},
+			Nodes: []*schema.Node{{
+				Id:               schema.TrimmedString("node1"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties:       schema.NodeProperties{InstanceType: schema.TrimmedString("t3.micro")},
+				RemoteAccess:     schema.NodeRemoteAccess{PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub")},
+				Network: &schema.NodeNetwork{
+					VpcId:             schema.TrimmedString("vpc-0a1b2c3d4e5f60718"),
+					SubnetId:          schema.TrimmedString("subnet-0a1b2c3d4e5f60718"),
+					AssociatePublicIP: func() *bool { associate := false; return &associate }(),
+					AllowedCIDRs: &schema.NodeAllowedCIDRs{
+						SSH:              []schema.TrimmedString{"10.0.0.0/8", "192.168.0.0/16"},
+						OpenVsCodeServer: []schema.TrimmedString{},
+					},
+				},
+			}},
+			Devcontainers: []*schema.Devcontainer{{
+				Id:           schema.TrimmedString("api"),
+				NodeId:       schema.TrimmedString("node1"),
+				Source:       &schema.DevcontainerSource{URL: schema.TrimmedString("https://github.com/example/api")},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}},
+		}, expectedNetwork},
+		{"access modes", &schema.DenvclustrRoot{
+			Name: schema.TrimmedString("private-cluster"),
+			Infrastructure: []*schema.Infrastructure{{
+				Id:       schema.TrimmedString("infrastructure1"),
+				Provider: schema.ProviderAws,
+				Kind:     schema.KindVm,
+				Region:   schema.TrimmedString("us-west-2"),
+			}},
+			Nodes: []*schema.Node{{
+				Id:               schema.TrimmedString("node1"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties:       schema.NodeProperties{InstanceType: schema.TrimmedString("t3.micro")},
+				RemoteAccess: schema.NodeRemoteAccess{
+					PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub"),
+					Mode:         schema.AccessModeBastion,
+					Bastion: &schema.NodeBastion{
+						Host: schema.TrimmedString("bastion.example.com"),
+						User: schema.TrimmedString("jump"),
+						Port: func() *int { port := 2022; return &port }(),
+					},
+				},
+			}, {
+				Id:               schema.TrimmedString("node2"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties:       schema.NodeProperties{InstanceType: schema.TrimmedString("t3.micro")},
+				RemoteAccess: schema.NodeRemoteAccess{
+					PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub"),
+					Mode:         schema.AccessModeSSMSession,
+				},
+			}},
+			Devcontainers: []*schema.Devcontainer{{
+				Id:           schema.TrimmedString("api"),
+				NodeId:       schema.TrimmedString("node1"),
+				Source:       &schema.DevcontainerSource{URL: schema.TrimmedString("https://github.com/example/api")},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{Ssh: &schema.DevcontainerSSH{}},
+			}, {
+				Id:           schema.TrimmedString("web"),
+				NodeId:       schema.TrimmedString("node2"),
+				Source:       &schema.DevcontainerSource{URL: schema.TrimmedString("https://github.com/example/web")},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{Ssh: &schema.DevcontainerSSH{}},
+			}},
+		}, expectedAccessModes},
+		{"spot", &schema.DenvclustrRoot{
+			Name: schema.TrimmedString("spot-cluster"),
+			Infrastructure: []*schema.Infrastructure{{
+				Id:       schema.TrimmedString("infrastructure1"),
+				Provider: schema.ProviderAws,
+				Kind:     schema.KindVm,
+				Region:   schema.TrimmedString("us-west-2"),
+			}},
+			Nodes: []*schema.Node{{
+				Id:               schema.TrimmedString("node1"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties: schema.NodeProperties{
+					InstanceType: schema.TrimmedString("t3.micro"),
+					Market:       schema.MarketSpot,
+					MaxPrice:     func() *float64 { price := 0.0052; return &price }(),
+				},
+				RemoteAccess: schema.NodeRemoteAccess{PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub")},
+			}, {
+				Id:               schema.TrimmedString("node2"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties: schema.NodeProperties{
+					InstanceType:         schema.TrimmedString("t3.micro"),
+					Market:               schema.MarketSpot,
+					InterruptionBehavior: schema.InterruptionBehaviorTerminate,
+				},
+				RemoteAccess: schema.NodeRemoteAccess{PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub")},
+			}},
+			Devcontainers: []*schema.Devcontainer{{
+				Id:           schema.TrimmedString("api"),
+				NodeId:       schema.TrimmedString("node1"),
+				Source:       &schema.DevcontainerSource{URL: schema.TrimmedString("https://github.com/example/api")},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}, {
+				Id:           schema.TrimmedString("web"),
+				NodeId:       schema.TrimmedString("node2"),
+				Source:       &schema.DevcontainerSource{URL: schema.TrimmedString("https://github.com/example/web")},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}},
+		}, expectedSpot},
+		{"schedule", &schema.DenvclustrRoot{
+			Name: schema.TrimmedString("office-hours-cluster"),
+			Infrastructure: []*schema.Infrastructure{{
+				Id:       schema.TrimmedString("infrastructure1"),
+				Provider: schema.ProviderAws,
+				Kind:     schema.KindVm,
+				Region:   schema.TrimmedString("eu-central-1"),
+			}},
+			Nodes: []*schema.Node{{
+				Id:               schema.TrimmedString("node1"),
+				InfrastructureId: schema.TrimmedString("infrastructure1"),
+				Properties:       schema.NodeProperties{InstanceType: schema.TrimmedString("t3.micro")},
+				RemoteAccess:     schema.NodeRemoteAccess{PublicSSHKey: schema.TrimmedString("~/.ssh/id_rsa.pub")},
+				Schedule: &schema.NodeSchedule{
+					Start:    schema.TrimmedString("0 8 * * MON-FRI"),
+					Stop:     schema.TrimmedString("30 19 * * 1-5"),
+					Timezone: schema.TrimmedString("Europe/Berlin"),
+				},
+			}},
+			Devcontainers: []*schema.Devcontainer{{
+				Id:           schema.TrimmedString("api"),
+				NodeId:       schema.TrimmedString("node1"),
+				Source:       &schema.DevcontainerSource{URL: schema.TrimmedString("https://github.com/example/api")},
+				RemoteAccess: &schema.DevcontainerRemoteAccess{OpenVsCodeServer: &schema.DevcontainerOpenVSCodeServer{}},
+			}},
+		}, expectedSchedule},
 	}
 
 	for _, c := range cases {
@@ -1,6 +1,8 @@
 package dc2tf
 
 import (
+	"strconv"
+
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/tropicaltux/denvclustr/pkg/schema"
@@ -13,18 +15,21 @@ func (c *converter) addModules(body *hclwrite.Body) error {
 		infrastructureById[string(infrastructure.Id)] = infrastructure
 	}
 
+	// Replicated devcontainers are expanded into their instances, unless the model is already postprocessed
 	devcontainerByNode := map[string][]*schema.Devcontainer{}
-	for _, devcontainer := range c.root.Devcontainers {
+	for _, devcontainer := range schema.ExpandReplicas(c.root.Devcontainers) {
 		devcontainerByNode[string(devcontainer.NodeId)] = append(devcontainerByNode[string(devcontainer.NodeId)], devcontainer)
 	}
 
 	for _, node := range c.root.Nodes {
 		devcontainers := devcontainerByNode[string(node.Id)]
+		infrastructure := infrastructureById[string(node.InfrastructureId)]
 
 		moduleBody := body.AppendNewBlock("module", []string{string(node.Id)}).Body()
 		moduleBody.SetAttributeValue("source", cty.StringVal("github.com/tropicaltux/terraform-devcontainers"))
 		moduleBody.SetAttributeValue("name", cty.StringVal(string(node.Id)))
 		moduleBody.SetAttributeValue("instance_type", cty.StringVal(string(node.Properties.InstanceType)))
+		c.writeNodeProperties(moduleBody, node)
 
 		// Replace provider attribute with providers attribute using raw tokens
 		providerTokens := hclwrite.Tokens{}
@@ -61,7 +66,7 @@ func (c *converter) addModules(body *hclwrite.Body) error {
 		})
 		providerTokens = append(providerTokens, &hclwrite.Token{
 			Type:  hclsyntax.TokenIdent,
-			Bytes: []byte(string(infrastructureById[string(node.InfrastructureId)].Id)),
+			Bytes: []byte(string(infrastructure.Id)),
 		})
 
 		// Closing brace
@@ -73,6 +78,11 @@ func (c *converter) addModules(body *hclwrite.Body) error {
 
 		moduleBody.SetAttributeRaw("providers", providerTokens)
 
+		// Resources not covered by the default tags of the provider, e.g. volumes, are tagged by the module
+		if tags := schema.MergeTags(c.root.Tags, infrastructure.Tags, node.Tags); len(tags) > 0 {
+			moduleBody.SetAttributeValue("tags", tagsValue(tags))
+		}
+
 		if err := c.writeDevcontainers(moduleBody, devcontainers, node); err != nil {
 			return err
 		}
@@ -80,6 +90,8 @@ func (c *converter) addModules(body *hclwrite.Body) error {
 			return err
 		}
 		c.writeDNS(moduleBody, node)
+		c.writeNetwork(moduleBody, node)
+		c.writeNodeAccess(moduleBody, node)
 	}
 	return nil
 }
@@ -91,23 +103,43 @@ func (c *converter) writeDevcontainers(body *hclwrite.Body, devcontainers []*sch
 		devcontainerMap := map[string]cty.Value{}
 		devcontainerMap["id"] = cty.StringVal(string(devcontainer.Id))
 
-		sourceMap := map[string]cty.Value{}
-		sourceMap["url"] = cty.StringVal(string(devcontainer.Source.URL))
-		if devcontainer.Source.Branch != "" {
-			sourceMap["branch"] = cty.StringVal(string(devcontainer.Source.Branch))
+		// Create source or image before remote_access to match order in etalon
+		if devcontainer.Source != nil {
+			devcontainerMap["source"] = sourceValue(devcontainer.Source)
+		}
+		if devcontainer.Image != nil {
+			devcontainerMap["image"] = imageValue(devcontainer.Image)
 		}
-		if devcontainer.Source.DevcontainerPath != "" {
-			sourceMap["devcontainer_path"] = cty.StringVal(string(devcontainer.Source.DevcontainerPath))
+		if len(devcontainer.AdditionalRepositories) > 0 {
+			var repositories []cty.Value
+			for _, repository := range devcontainer.AdditionalRepositories {
+				repositories = append(repositories, repositoryValue(repository))
+			}
+			devcontainerMap["additional_repositories"] = cty.TupleVal(repositories)
 		}
-		if devcontainer.Source.SshKey != nil {
-			sshKeyMap := map[string]cty.Value{}
-			sshKeyMap["ref"] = cty.StringVal(string(devcontainer.Source.SshKey.Reference))
-			sshKeyMap["src"] = cty.StringVal(string(devcontainer.Source.SshKey.Source))
-			sourceMap["ssh_key"] = cty.ObjectVal(sshKeyMap)
+		if len(devcontainer.Env) > 0 {
+			env := map[string]cty.Value{}
+			for name, value := range devcontainer.Env {
+				env[name] = cty.StringVal(string(value))
+			}
+			devcontainerMap["env"] = cty.MapVal(env)
+		}
+		if len(devcontainer.Secrets) > 0 {
+			// Only the references are written, the module reads the values on the node
+			var secrets []cty.Value
+			for _, secret := range devcontainer.Secrets {
+				secrets = append(secrets, cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal(string(secret.Name)),
+					"ref":  cty.StringVal(string(secret.Reference)),
+					"src":  cty.StringVal(string(secret.Source)),
+				}))
+			}
+			devcontainerMap["secrets"] = cty.TupleVal(secrets)
+		}
+		if len(devcontainer.Tags) > 0 {
+			// The module merges the tags of the devcontainer with its own
+			devcontainerMap["tags"] = tagsValue(devcontainer.Tags)
 		}
-
-		// Create remote_access block - adding source before remote_access to match order in etalon
-		devcontainerMap["source"] = cty.ObjectVal(sourceMap)
 
 		if devcontainer.RemoteAccess != nil {
 			remoteAccessMap := map[string]cty.Value{}
@@ -140,12 +172,50 @@ func (c *converter) writeDevcontainers(body *hclwrite.Body, devcontainers []*sch
 	if len(devcontainerItems) == 0 {
 		body.SetAttributeValue("devcontainers", cty.ListValEmpty(cty.DynamicPseudoType))
 	} else {
-		body.SetAttributeValue("devcontainers", cty.ListVal(devcontainerItems))
+		// Devcontainers may set different attributes, so they are written as a tuple rather than a list of a single type
+		body.SetAttributeValue("devcontainers", cty.TupleVal(devcontainerItems))
 	}
 
 	return nil
 }
 
+func (c *converter) writeNodeProperties(body *hclwrite.Body, node *schema.Node) {
+	properties := node.Properties
+	if properties.Architecture != "" {
+		body.SetAttributeValue("architecture", cty.StringVal(string(properties.Architecture)))
+	}
+	if properties.AMI != "" {
+		body.SetAttributeValue("ami", cty.StringVal(string(properties.AMI)))
+	}
+	if volume := properties.RootVolume; volume != nil {
+		volumeMap := map[string]cty.Value{}
+		if volume.Size != nil {
+			volumeMap["size"] = cty.NumberIntVal(int64(*volume.Size))
+		}
+		if volume.Encrypted != nil {
+			volumeMap["encrypted"] = cty.BoolVal(*volume.Encrypted)
+		}
+		if volume.KmsKeyId != "" {
+			volumeMap["kms_key_id"] = cty.StringVal(string(volume.KmsKeyId))
+		}
+		body.SetAttributeValue("root_volume", cty.ObjectVal(volumeMap))
+	}
+	if properties.RequireIMDSv2 != nil {
+		body.SetAttributeValue("require_imdsv2", cty.BoolVal(*properties.RequireIMDSv2))
+	}
+	if properties.MarketType() == schema.MarketSpot {
+		// The interruption behavior is always written, as stop is not the default of AWS
+		marketMap := map[string]cty.Value{
+			"type":                  cty.StringVal(string(schema.MarketSpot)),
+			"interruption_behavior": cty.StringVal(string(properties.SpotInterruptionBehavior())),
+		}
+		if properties.MaxPrice != nil {
+			marketMap["max_price"] = cty.StringVal(strconv.FormatFloat(*properties.MaxPrice, 'f', -1, 64))
+		}
+		body.SetAttributeValue("market", cty.ObjectVal(marketMap))
+	}
+}
+
 func (c *converter) writeNodeSSH(body *hclwrite.Body, node *schema.Node) error {
 	body.SetAttributeValue("public_ssh_key", cty.ObjectVal(map[string]cty.Value{
 		"local_key_path": cty.StringVal(string(node.RemoteAccess.PublicSSHKey)),
@@ -160,3 +230,134 @@ func (c *converter) writeDNS(body *hclwrite.Body, node *schema.Node) {
 		}))
 	}
 }
+
+func (c *converter) writeNetwork(body *hclwrite.Body, node *schema.Node) {
+	network := node.Network
+	if network == nil {
+		return
+	}
+	networkMap := map[string]cty.Value{}
+	if network.VpcId != "" {
+		networkMap["vpc_id"] = cty.StringVal(string(network.VpcId))
+	}
+	if network.SubnetId != "" {
+		networkMap["subnet_id"] = cty.StringVal(string(network.SubnetId))
+	}
+	if network.AssociatePublicIP != nil {
+		networkMap["associate_public_ip"] = cty.BoolVal(*network.AssociatePublicIP)
+	}
+	if network.AllowedCIDRs != nil {
+		// An empty list is kept, as it closes the port rather than using the default of the module
+		cidrsMap := map[string]cty.Value{}
+		if network.AllowedCIDRs.SSH != nil {
+			cidrsMap["ssh"] = stringsValue(network.AllowedCIDRs.SSH)
+		}
+		if network.AllowedCIDRs.OpenVsCodeServer != nil {
+			cidrsMap["openvscode_server"] = stringsValue(network.AllowedCIDRs.OpenVsCodeServer)
+		}
+		networkMap["allowed_cidrs"] = cty.ObjectVal(cidrsMap)
+	}
+	body.SetAttributeValue("network", cty.ObjectVal(networkMap))
+}
+
+// writeNodeAccess sets how the node is reached when it is not over its public IP address.
+// The module then neither assigns a public IP address nor opens the ports to the internet.
+func (c *converter) writeNodeAccess(body *hclwrite.Body, node *schema.Node) {
+	mode := node.RemoteAccess.AccessMode()
+	if mode == schema.AccessModePublic {
+		return
+	}
+	accessMap := map[string]cty.Value{"mode": cty.StringVal(string(mode))}
+	if bastion := node.RemoteAccess.Bastion; bastion != nil {
+		bastionMap := map[string]cty.Value{"host": cty.StringVal(string(bastion.Host))}
+		if bastion.User != "" {
+			bastionMap["user"] = cty.StringVal(string(bastion.User))
+		}
+		if bastion.Port != nil {
+			bastionMap["port"] = cty.NumberIntVal(int64(*bastion.Port))
+		}
+		accessMap["bastion"] = cty.ObjectVal(bastionMap)
+	}
+	body.SetAttributeValue("access", cty.ObjectVal(accessMap))
+}
+
+// stringsValue returns a list of strings, which may be empty.
+func stringsValue(values []schema.TrimmedString) cty.Value {
+	if len(values) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+	items := make([]cty.Value, len(values))
+	for i, value := range values {
+		items[i] = cty.StringVal(string(value))
+	}
+	return cty.ListVal(items)
+}
+
+// sourceValue returns the module input describing the repository a devcontainer is built from.
+func sourceValue(source *schema.DevcontainerSource) cty.Value {
+	sourceMap := map[string]cty.Value{}
+	sourceMap["url"] = cty.StringVal(string(source.URL))
+	if source.Branch != "" {
+		sourceMap["branch"] = cty.StringVal(string(source.Branch))
+	}
+	if source.Tag != "" {
+		sourceMap["tag"] = cty.StringVal(string(source.Tag))
+	}
+	if source.Commit != "" {
+		sourceMap["commit"] = cty.StringVal(string(source.Commit))
+	}
+	if source.DevcontainerPath != "" {
+		sourceMap["devcontainer_path"] = cty.StringVal(string(source.DevcontainerPath))
+	}
+	if source.SshKey != nil {
+		sourceMap["ssh_key"] = sshKeyValue(source.SshKey)
+	}
+	return cty.ObjectVal(sourceMap)
+}
+
+// repositoryValue returns the module input describing a repository cloned into the workspace of a devcontainer.
+func repositoryValue(repository *schema.DevcontainerRepository) cty.Value {
+	repositoryMap := map[string]cty.Value{}
+	repositoryMap["url"] = cty.StringVal(string(repository.URL))
+	repositoryMap["path"] = cty.StringVal(string(repository.Path))
+	if repository.Branch != "" {
+		repositoryMap["branch"] = cty.StringVal(string(repository.Branch))
+	}
+	if repository.Tag != "" {
+		repositoryMap["tag"] = cty.StringVal(string(repository.Tag))
+	}
+	if repository.SshKey != nil {
+		repositoryMap["ssh_key"] = sshKeyValue(repository.SshKey)
+	}
+	return cty.ObjectVal(repositoryMap)
+}
+
+// tagsValue returns the module input setting the tags of AWS resources.
+func tagsValue(tags map[string]schema.TrimmedString) cty.Value {
+	values := map[string]cty.Value{}
+	for key, value := range tags {
+		values[key] = cty.StringVal(string(value))
+	}
+	return cty.MapVal(values)
+}
+
+// sshKeyValue returns the module input locating the private SSH key of a repository in a secret backend.
+func sshKeyValue(sshKey *schema.DevcontainerSourceSSHKey) cty.Value {
+	return cty.ObjectVal(map[string]cty.Value{
+		"ref": cty.StringVal(string(sshKey.Reference)),
+		"src": cty.StringVal(string(sshKey.Source)),
+	})
+}
+
+// imageValue returns the module input describing the prebuilt image a devcontainer runs.
+func imageValue(image *schema.DevcontainerImage) cty.Value {
+	imageMap := map[string]cty.Value{}
+	imageMap["reference"] = cty.StringVal(string(image.Reference))
+	if image.Credentials != nil {
+		imageMap["credentials"] = cty.ObjectVal(map[string]cty.Value{
+			"ref": cty.StringVal(string(image.Credentials.Reference)),
+			"src": cty.StringVal(string(image.Credentials.Source)),
+		})
+	}
+	return cty.ObjectVal(imageMap)
+}
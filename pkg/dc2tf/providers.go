@@ -16,6 +16,11 @@ func (c *converter) addProviders(body *hclwrite.Body) error {
 		providerBody := body.AppendNewBlock("provider", []string{"aws"}).Body()
 		providerBody.SetAttributeValue("region", cty.StringVal(string(infrastructure.Region)))
 		providerBody.SetAttributeValue("alias", cty.StringVal(string(infrastructure.Id)))
+
+		// Tags of the cluster and the infrastructure are applied to every resource created with the provider
+		if tags := schema.MergeTags(c.root.Tags, infrastructure.Tags); len(tags) > 0 {
+			providerBody.AppendNewBlock("default_tags", nil).Body().SetAttributeValue("tags", tagsValue(tags))
+		}
 	}
 	return nil
 }
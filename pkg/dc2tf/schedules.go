@@ -0,0 +1,96 @@
+package dc2tf
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/tropicaltux/denvclustr/pkg/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// addSchedules starts and stops the instances of scheduled nodes with EventBridge Scheduler. Each scheduled
+// node gets a role allowing the scheduler to start and stop its instance, and a schedule for each of both.
+func (c *converter) addSchedules(body *hclwrite.Body) error {
+	infrastructureById := map[string]*schema.Infrastructure{}
+	for _, infrastructure := range c.root.Infrastructure {
+		infrastructureById[string(infrastructure.Id)] = infrastructure
+	}
+
+	for _, node := range c.root.Nodes {
+		if node.Schedule == nil {
+			continue
+		}
+		infrastructure := infrastructureById[string(node.InfrastructureId)]
+		if infrastructure == nil {
+			return fmt.Errorf("node %q: unknown infrastructure %q", node.Id, node.InfrastructureId)
+		}
+		timezone := "UTC"
+		if node.Schedule.Timezone != "" {
+			timezone = string(node.Schedule.Timezone)
+		}
+
+		provider := hcl.Traversal{hcl.TraverseRoot{Name: "aws"}, hcl.TraverseAttr{Name: string(infrastructure.Id)}}
+		instanceId := hcl.Traversal{hcl.TraverseRoot{Name: "module"}, hcl.TraverseAttr{Name: string(node.Id)}, hcl.TraverseAttr{Name: "instance_id"}}
+		roleName := fmt.Sprintf("%s_scheduler", node.Id)
+
+		roleBody := body.AppendNewBlock("resource", []string{"aws_iam_role", roleName}).Body()
+		roleBody.SetAttributeTraversal("provider", provider)
+		roleBody.SetAttributeValue("name_prefix", cty.StringVal(fmt.Sprintf("%s-scheduler-", node.Id)))
+		roleBody.SetAttributeRaw("assume_role_policy", hclwrite.TokensForFunctionCall("jsonencode", hclwrite.TokensForValue(cty.ObjectVal(map[string]cty.Value{
+			"Version": cty.StringVal("2012-10-17"),
+			"Statement": cty.TupleVal([]cty.Value{cty.ObjectVal(map[string]cty.Value{
+				"Effect":    cty.StringVal("Allow"),
+				"Principal": cty.ObjectVal(map[string]cty.Value{"Service": cty.StringVal("scheduler.amazonaws.com")}),
+				"Action":    cty.StringVal("sts:AssumeRole"),
+			})}),
+		}))))
+
+		// The role may only start and stop the instance of the node
+		policyBody := body.AppendNewBlock("resource", []string{"aws_iam_role_policy", roleName}).Body()
+		policyBody.SetAttributeTraversal("provider", provider)
+		policyBody.SetAttributeTraversal("role", hcl.Traversal{hcl.TraverseRoot{Name: "aws_iam_role"}, hcl.TraverseAttr{Name: roleName}, hcl.TraverseAttr{Name: "id"}})
+		policyBody.SetAttributeRaw("policy", hclwrite.TokensForFunctionCall("jsonencode", hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{
+			{Name: hclwrite.TokensForIdentifier("Version"), Value: hclwrite.TokensForValue(cty.StringVal("2012-10-17"))},
+			{Name: hclwrite.TokensForIdentifier("Statement"), Value: hclwrite.TokensForTuple([]hclwrite.Tokens{hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{
+				{Name: hclwrite.TokensForIdentifier("Effect"), Value: hclwrite.TokensForValue(cty.StringVal("Allow"))},
+				{Name: hclwrite.TokensForIdentifier("Action"), Value: hclwrite.TokensForValue(cty.TupleVal([]cty.Value{
+					cty.StringVal("ec2:StartInstances"), cty.StringVal("ec2:StopInstances"),
+				}))},
+				{Name: hclwrite.TokensForIdentifier("Resource"), Value: hclwrite.TokensForFunctionCall("format",
+					hclwrite.TokensForValue(cty.StringVal(fmt.Sprintf("arn:aws:ec2:%s:*:instance/%%s", infrastructure.Region))),
+					hclwrite.TokensForTraversal(instanceId),
+				)},
+			})})},
+		})))
+
+		for _, schedule := range []struct {
+			action     string
+			api        string
+			expression schema.TrimmedString
+		}{
+			{"start", "startInstances", node.Schedule.Start},
+			{"stop", "stopInstances", node.Schedule.Stop},
+		} {
+			cron, err := schema.ParseCron(string(schedule.expression))
+			if err != nil {
+				return fmt.Errorf("node %q: schedule %s: %w", node.Id, schedule.action, err)
+			}
+
+			scheduleBody := body.AppendNewBlock("resource", []string{"aws_scheduler_schedule", fmt.Sprintf("%s_%s", node.Id, schedule.action)}).Body()
+			scheduleBody.SetAttributeTraversal("provider", provider)
+			scheduleBody.SetAttributeValue("name_prefix", cty.StringVal(fmt.Sprintf("%s-%s-", node.Id, schedule.action)))
+			scheduleBody.SetAttributeValue("schedule_expression", cty.StringVal(cron.EventBridge()))
+			scheduleBody.SetAttributeValue("schedule_expression_timezone", cty.StringVal(timezone))
+			scheduleBody.AppendNewBlock("flexible_time_window", nil).Body().SetAttributeValue("mode", cty.StringVal("OFF"))
+
+			targetBody := scheduleBody.AppendNewBlock("target", nil).Body()
+			targetBody.SetAttributeValue("arn", cty.StringVal("arn:aws:scheduler:::aws-sdk:ec2:"+schedule.api))
+			targetBody.SetAttributeTraversal("role_arn", hcl.Traversal{hcl.TraverseRoot{Name: "aws_iam_role"}, hcl.TraverseAttr{Name: roleName}, hcl.TraverseAttr{Name: "arn"}})
+			targetBody.SetAttributeRaw("input", hclwrite.TokensForFunctionCall("jsonencode", hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{
+				{Name: hclwrite.TokensForIdentifier("InstanceIds"), Value: hclwrite.TokensForTuple([]hclwrite.Tokens{hclwrite.TokensForTraversal(instanceId)})},
+			})))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+provider "aws" {
+  region = "us-west-2"
+  alias  = "infrastructure1"
+}
+
+module "node1" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node1"
+  instance_type = "t3.micro"
+  providers     = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "api"
+      source = {
+        url = "https://github.com/example/api"
+      }
+      remote_access = {
+        ssh = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+
+  access = {
+    mode = "bastion"
+    bastion = {
+      host = "bastion.example.com"
+      user = "jump"
+      port = 2022
+    }
+  }
+}
+
+module "node2" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node2"
+  instance_type = "t3.micro"
+  providers     = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "web"
+      source = {
+        url = "https://github.com/example/web"
+      }
+      remote_access = {
+        ssh = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+
+  access = {
+    mode = "ssm_session"
+  }
+}
+
+output "node1_output" {
+  value     = {
+    module = module.node1
+  }
+  
+}
+
+output "node2_output" {
+  value     = {
+    module = module.node2
+  }
+  
+}
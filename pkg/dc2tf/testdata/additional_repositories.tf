@@ -0,0 +1,52 @@
+provider "aws" {
+  region = "us-west-2"
+  alias  = "infrastructure1"
+}
+
+module "node1" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node1"
+  instance_type = "t3.micro"
+  providers     = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "platform"
+      source = {
+        url = "https://github.com/example/platform"
+      }
+      additional_repositories = [
+        {
+          url  = "https://github.com/example/billing"
+          path = "services/billing"
+          tag  = "v2.0.1"
+        },
+        {
+          url    = "git@github.com:example/identity.git"
+          path   = "services/identity"
+          branch = "main"
+          ssh_key = {
+            ref = "/keys/identity"
+            src = "ssm_parameter_store"
+          }
+        }
+      ]
+      remote_access = {
+        openvscode_server = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+}
+
+output "node1_output" {
+  value     = {
+    module = module.node1
+  }
+  
+}
@@ -0,0 +1,52 @@
+provider "aws" {
+  region = "us-west-2"
+  alias  = "infrastructure1"
+}
+
+module "node1" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node1"
+  instance_type = "t3.micro"
+  providers     = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "api"
+      source = {
+        url = "https://github.com/example/api"
+      }
+      env = {
+        API_URL   = "https://api.staging.example.com"
+        LOG_LEVEL = "debug"
+      }
+      secrets = [
+        {
+          name = "API_TOKEN"
+          ref  = "staging/api-token"
+          src  = "secrets_manager"
+        },
+        {
+          name = "DATABASE_PASSWORD"
+          ref  = "/staging/database/password"
+          src  = "ssm_parameter_store"
+        }
+      ]
+      remote_access = {
+        openvscode_server = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+}
+
+output "node1_output" {
+  value     = {
+    module = module.node1
+  }
+  
+}
@@ -0,0 +1,49 @@
+provider "aws" {
+  region = "us-west-2"
+  alias  = "infrastructure1"
+}
+
+module "node1" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node1"
+  instance_type = "t3.micro"
+  providers     = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "api"
+      image = {
+        reference = "ghcr.io/example/api-dev:1.4.2"
+        credentials = {
+          ref = "registry-credentials"
+          src = "secrets_manager"
+        }
+      }
+      remote_access = {
+        openvscode_server = {}
+      }
+    },
+    {
+      id = "tools"
+      image = {
+        reference = "ubuntu:24.04"
+      }
+      remote_access = {
+        openvscode_server = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+}
+
+output "node1_output" {
+  value     = {
+    module = module.node1
+  }
+  
+}
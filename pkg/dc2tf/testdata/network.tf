@@ -0,0 +1,46 @@
+provider "aws" {
+  region = "us-west-2"
+  alias  = "infrastructure1"
+}
+
+module "node1" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node1"
+  instance_type = "t3.micro"
+  providers     = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "api"
+      source = {
+        url = "https://github.com/example/api"
+      }
+      remote_access = {
+        openvscode_server = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+
+  network = {
+    vpc_id              = "vpc-0a1b2c3d4e5f60718"
+    subnet_id           = "subnet-0a1b2c3d4e5f60718"
+    associate_public_ip = false
+    allowed_cidrs = {
+      ssh               = ["10.0.0.0/8", "192.168.0.0/16"]
+      openvscode_server = []
+    }
+  }
+}
+
+output "node1_output" {
+  value     = {
+    module = module.node1
+  }
+  
+}
@@ -0,0 +1,44 @@
+provider "aws" {
+  region = "us-west-2"
+  alias  = "infrastructure1"
+}
+
+module "node1" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node1"
+  instance_type = "m7g.large"
+  architecture  = "arm64"
+  ami           = "ami-0123456789abcdef0"
+  root_volume = {
+    size       = 100
+    encrypted  = true
+    kms_key_id = "alias/dev-volumes"
+  }
+  require_imdsv2 = true
+  providers      = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "api"
+      source = {
+        url = "https://github.com/example/api"
+      }
+      remote_access = {
+        openvscode_server = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+}
+
+output "node1_output" {
+  value     = {
+    module = module.node1
+  }
+  
+}
@@ -0,0 +1,47 @@
+provider "aws" {
+  region = "us-west-2"
+  alias  = "infrastructure1"
+}
+
+module "node1" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node1"
+  instance_type = "t3.micro"
+  providers     = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "release"
+      source = {
+        url = "https://github.com/example/repo"
+        tag = "v1.4.2"
+      }
+      remote_access = {
+        openvscode_server = {}
+      }
+    },
+    {
+      id = "bisect"
+      source = {
+        url    = "https://github.com/example/repo"
+        commit = "9fceb02d0ae598e95dc970b74767f19372d61af8"
+      }
+      remote_access = {
+        openvscode_server = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+}
+
+output "node1_output" {
+  value     = {
+    module = module.node1
+  }
+  
+}
@@ -0,0 +1,59 @@
+provider "aws" {
+  region = "us-west-2"
+  alias  = "infrastructure1"
+}
+
+module "node1" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node1"
+  instance_type = "t3.xlarge"
+  providers     = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "api-dev-01"
+      source = {
+        url = "https://github.com/example/api"
+      }
+      remote_access = {
+        ssh = {
+          port = 2200
+        }
+      }
+    },
+    {
+      id = "api-dev-02"
+      source = {
+        url = "https://github.com/example/api"
+      }
+      remote_access = {
+        ssh = {
+          port = 2201
+        }
+      }
+    },
+    {
+      id = "docs"
+      source = {
+        url = "https://github.com/example/docs"
+        branch = "main"
+      }
+      remote_access = {
+        openvscode_server = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+}
+
+output "node1_output" {
+  value     = {
+    module = module.node1
+  }
+  
+}
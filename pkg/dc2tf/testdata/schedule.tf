@@ -0,0 +1,98 @@
+provider "aws" {
+  region = "eu-central-1"
+  alias  = "infrastructure1"
+}
+
+module "node1" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node1"
+  instance_type = "t3.micro"
+  providers     = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "api"
+      source = {
+        url = "https://github.com/example/api"
+      }
+      remote_access = {
+        openvscode_server = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+}
+
+resource "aws_iam_role" "node1_scheduler" {
+  provider    = aws.infrastructure1
+  name_prefix = "node1-scheduler-"
+  assume_role_policy = jsonencode({
+    Statement = [{
+      Action = "sts:AssumeRole"
+      Effect = "Allow"
+      Principal = {
+        Service = "scheduler.amazonaws.com"
+      }
+    }]
+    Version = "2012-10-17"
+  })
+}
+
+resource "aws_iam_role_policy" "node1_scheduler" {
+  provider = aws.infrastructure1
+  role     = aws_iam_role.node1_scheduler.id
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect   = "Allow"
+      Action   = ["ec2:StartInstances", "ec2:StopInstances"]
+      Resource = format("arn:aws:ec2:eu-central-1:*:instance/%s", module.node1.instance_id)
+    }]
+  })
+}
+
+resource "aws_scheduler_schedule" "node1_start" {
+  provider                     = aws.infrastructure1
+  name_prefix                  = "node1-start-"
+  schedule_expression          = "cron(0 8 ? * MON-FRI *)"
+  schedule_expression_timezone = "Europe/Berlin"
+  flexible_time_window {
+    mode = "OFF"
+  }
+  target {
+    arn      = "arn:aws:scheduler:::aws-sdk:ec2:startInstances"
+    role_arn = aws_iam_role.node1_scheduler.arn
+    input = jsonencode({
+      InstanceIds = [module.node1.instance_id]
+    })
+  }
+}
+
+resource "aws_scheduler_schedule" "node1_stop" {
+  provider                     = aws.infrastructure1
+  name_prefix                  = "node1-stop-"
+  schedule_expression          = "cron(30 19 ? * MON-FRI *)"
+  schedule_expression_timezone = "Europe/Berlin"
+  flexible_time_window {
+    mode = "OFF"
+  }
+  target {
+    arn      = "arn:aws:scheduler:::aws-sdk:ec2:stopInstances"
+    role_arn = aws_iam_role.node1_scheduler.arn
+    input = jsonencode({
+      InstanceIds = [module.node1.instance_id]
+    })
+  }
+}
+
+output "node1_output" {
+  value     = {
+    module = module.node1
+  }
+  
+}
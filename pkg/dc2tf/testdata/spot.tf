@@ -0,0 +1,77 @@
+provider "aws" {
+  region = "us-west-2"
+  alias  = "infrastructure1"
+}
+
+module "node1" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node1"
+  instance_type = "t3.micro"
+  market = {
+    type                  = "spot"
+    max_price             = "0.0052"
+    interruption_behavior = "stop"
+  }
+  providers = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "api"
+      source = {
+        url = "https://github.com/example/api"
+      }
+      remote_access = {
+        openvscode_server = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+}
+
+module "node2" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node2"
+  instance_type = "t3.micro"
+  market = {
+    type                  = "spot"
+    interruption_behavior = "terminate"
+  }
+  providers = {
+    aws = aws.infrastructure1
+  }
+
+  devcontainers = [
+    {
+      id = "web"
+      source = {
+        url = "https://github.com/example/web"
+      }
+      remote_access = {
+        openvscode_server = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+}
+
+output "node1_output" {
+  value     = {
+    module = module.node1
+  }
+  
+}
+
+output "node2_output" {
+  value     = {
+    module = module.node2
+  }
+  
+}
@@ -0,0 +1,52 @@
+provider "aws" {
+  region = "us-west-2"
+  alias  = "infrastructure1"
+
+  default_tags {
+    tags = {
+      "cost-center" = "platform"
+      Environment   = "staging"
+      Owner         = "platform-team"
+    }
+  }
+}
+
+module "node1" {
+  source        = "github.com/tropicaltux/terraform-devcontainers"
+  name          = "node1"
+  instance_type = "t3.micro"
+  providers     = {
+    aws = aws.infrastructure1
+  }
+  tags = {
+    "cost-center" = "platform"
+    Environment   = "staging"
+    Owner         = "api-team"
+  }
+
+  devcontainers = [
+    {
+      id = "api"
+      source = {
+        url = "https://github.com/example/api"
+      }
+      tags = {
+        Project = "api"
+      }
+      remote_access = {
+        openvscode_server = {}
+      }
+    }
+  ]
+
+  public_ssh_key = {
+    local_key_path = "~/.ssh/id_rsa.pub"
+  }
+}
+
+output "node1_output" {
+  value     = {
+    module = module.node1
+  }
+  
+}
@@ -0,0 +1,34 @@
+package schema
+
+// validateAccessModes checks that nodes reached through a bastion set it, and that nodes
+// reached through a bastion or an SSM session need no public IP address.
+func validateAccessModes(root *DenvclustrRoot, c *collector) {
+	for i, node := range root.Nodes {
+		access := node.RemoteAccess
+		mode := access.AccessMode()
+		p := pointer("nodes", i, "remote_access")
+
+		switch {
+		case mode == AccessModeBastion && access.Bastion == nil:
+			c.add(RuleMissingField, p+pointer("bastion"), "node %q: bastion must be provided when mode is %q", node.Id, mode)
+		case mode != AccessModeBastion && access.Bastion != nil:
+			c.add(RuleConflictingFields, p+pointer("bastion"), "node %q: bastion can only be used when mode is %q, not %q", node.Id, AccessModeBastion, mode)
+		case access.Bastion != nil && access.Bastion.Host != "":
+			if err := checkHost(string(access.Bastion.Host)); err != nil {
+				c.add(RuleInvalidHost, p+pointer("bastion", "host"), "node %q: bastion.host: %v", node.Id, err)
+			}
+		}
+
+		if mode == AccessModePublic {
+			continue
+		}
+		if node.DNS != nil {
+			c.add(RuleConflictingFields, pointer("nodes", i, "dns"), "node %q: dns requires a public IP address, which mode %q does not use", node.Id, mode)
+		}
+		if node.Network != nil && node.Network.AssociatePublicIP != nil && *node.Network.AssociatePublicIP {
+			c.add(RuleConflictingFields, pointer("nodes", i, "network", "associate_public_ip"),
+				"node %q: associate_public_ip cannot be true when mode is %q, the node is not reached over its public IP address", node.Id, mode,
+			)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAccessModes(t *testing.T) {
+	const base = `name: private-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - id: node1
+    infrastructure_id: aws
+    properties: {instance_type: t3.large}
+    remote_access: %s
+devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}}
+`
+
+	t.Run("bastion", func(t *testing.T) {
+		root, err := Parse([]byte(fmt.Sprintf(base,
+			`{public_ssh_key: ~/.ssh/id.pub, mode: bastion, bastion: {host: bastion.example.com, user: jump, port: 2022}}`,
+		)), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		access := root.Nodes[0].RemoteAccess
+		assert.Equal(t, AccessModeBastion, access.AccessMode())
+		assert.Equal(t, TrimmedString("bastion.example.com"), access.Bastion.Host)
+	})
+
+	t.Run("public by default", func(t *testing.T) {
+		root, err := Parse([]byte(fmt.Sprintf(base, `{public_ssh_key: ~/.ssh/id.pub}`)), WithFormat(FormatYAML))
+		require.NoError(t, err)
+		assert.Equal(t, AccessModePublic, root.Nodes[0].RemoteAccess.AccessMode())
+	})
+
+	tests := []struct {
+		name    string
+		access  string
+		code    string
+		pointer string
+		message string
+	}{
+		{"bastion mode without bastion", `{public_ssh_key: ~/.ssh/id.pub, mode: bastion}`, RuleMissingField,
+			"/nodes/0/remote_access/bastion", `node "node1": bastion must be provided when mode is "bastion"`},
+		{"bastion with another mode", `{public_ssh_key: ~/.ssh/id.pub, mode: ssm_session, bastion: {host: bastion.example.com}}`, RuleConflictingFields,
+			"/nodes/0/remote_access/bastion", `node "node1": bastion can only be used when mode is "bastion", not "ssm_session"`},
+		{"invalid bastion host", `{public_ssh_key: ~/.ssh/id.pub, mode: bastion, bastion: {host: "bastion_host"}}`, RuleInvalidHost,
+			"/nodes/0/remote_access/bastion/host", `node "node1": bastion.host: invalid host "bastion_host"`},
+		{"unknown mode", `{public_ssh_key: ~/.ssh/id.pub, mode: vpn}`, RuleSchemaPrefix + "enum",
+			"/nodes/0/remote_access/mode", "value must be one of 'public', 'bastion', 'ssm_session'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate([]byte(fmt.Sprintf(base, tt.access)), WithFormat(FormatYAML))
+			require.NoError(t, err)
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.code, errs[0].Code)
+			assert.Equal(t, tt.pointer, errs[0].Pointer)
+			assert.Equal(t, tt.message, errs[0].Message)
+		})
+	}
+
+	t.Run("SSM session with public IP", func(t *testing.T) {
+		errs, err := Validate([]byte(`name: private-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - id: node1
+    infrastructure_id: aws
+    properties: {instance_type: t3.large}
+    remote_access: {public_ssh_key: ~/.ssh/id.pub, mode: ssm_session}
+    dns: {high_level_domain: dev.example.com}
+    network: {associate_public_ip: true}
+devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}}
+`), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		require.Len(t, errs, 2)
+		assert.Equal(t, "/nodes/0/dns", errs[0].Pointer)
+		assert.Equal(t, `node "node1": dns requires a public IP address, which mode "ssm_session" does not use`, errs[0].Message)
+		assert.Equal(t, "/nodes/0/network/associate_public_ip", errs[1].Pointer)
+	})
+}
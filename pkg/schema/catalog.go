@@ -0,0 +1,233 @@
+package schema
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// embeddedCatalog is the catalog used when no other is given. It is updated with each release.
+//
+//go:embed catalog.json
+var embeddedCatalog []byte
+
+var (
+	defaultCatalogOnce sync.Once
+	defaultCatalog     Catalog
+)
+
+// Catalog lists the regions and instance types offered by each provider.
+// It is used to find typos in regions and instance types before anything is deployed.
+type Catalog map[Provider]*ProviderCatalog
+
+// ProviderCatalog lists the regions and instance types offered by a provider.
+type ProviderCatalog struct {
+	Regions       []string                `json:"regions"`
+	InstanceTypes map[string]InstanceType `json:"instance_types"`
+}
+
+// InstanceType describes the machine of an instance type.
+type InstanceType struct {
+	VCPUs        int     `json:"vcpus"`
+	MemoryGiB    float64 `json:"memory_gib"`
+	Architecture string  `json:"architecture"`
+}
+
+// DefaultCatalog returns the catalog embedded in denvclustr.
+func DefaultCatalog() Catalog {
+	defaultCatalogOnce.Do(func() {
+		catalog, err := ParseCatalog(embeddedCatalog, FormatJSON)
+		if err != nil {
+			panic(fmt.Sprintf("invalid embedded catalog: %v", err))
+		}
+		defaultCatalog = catalog
+	})
+	return defaultCatalog
+}
+
+// ParseCatalog parses a catalog file, an object mapping provider names to their regions and
+// instance types, in the same format as the embedded catalog.
+func ParseCatalog(data []byte, format Format) (Catalog, error) {
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return nil, err
+	}
+	jsonData, err := doc.marshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog Catalog
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&catalog); err != nil {
+		return nil, fmt.Errorf("decode catalog: %w", err)
+	}
+	for provider, entry := range catalog {
+		if entry == nil || (len(entry.Regions) == 0 && len(entry.InstanceTypes) == 0) {
+			return nil, fmt.Errorf("catalog of provider %q must list regions or instance_types", provider)
+		}
+	}
+	return catalog, nil
+}
+
+// Override returns a catalog where the regions and instance types of other are added to those of c.
+// Instance types defined by both are described as in other. Neither catalog is modified.
+func (c Catalog) Override(other Catalog) Catalog {
+	result := make(Catalog, len(c)+len(other))
+	for provider, entry := range c {
+		result[provider] = entry
+	}
+	for provider, entry := range other {
+		base, ok := result[provider]
+		if !ok {
+			result[provider] = entry
+			continue
+		}
+
+		merged := &ProviderCatalog{
+			Regions:       append([]string(nil), base.Regions...),
+			InstanceTypes: make(map[string]InstanceType, len(base.InstanceTypes)+len(entry.InstanceTypes)),
+		}
+		for _, region := range entry.Regions {
+			if !contains(merged.Regions, region) {
+				merged.Regions = append(merged.Regions, region)
+			}
+		}
+		for name, instanceType := range base.InstanceTypes {
+			merged.InstanceTypes[name] = instanceType
+		}
+		for name, instanceType := range entry.InstanceTypes {
+			merged.InstanceTypes[name] = instanceType
+		}
+		result[provider] = merged
+	}
+	return result
+}
+
+// InstanceType returns the description of an instance type of a provider.
+func (c Catalog) InstanceType(provider Provider, name string) (InstanceType, bool) {
+	entry, ok := c[provider]
+	if !ok {
+		return InstanceType{}, false
+	}
+	instanceType, ok := entry.InstanceTypes[name]
+	return instanceType, ok
+}
+
+// validateCatalog checks the regions of the infrastructure and the instance types of the nodes
+// against the catalog. Providers missing from the catalog are not checked. Unknown values are
+// warnings, as the catalog may not list regions and instance types offered since its release.
+func validateCatalog(root *DenvclustrRoot, catalog Catalog, c *collector) {
+	providers := make(map[string]Provider)
+	for i, infrastructure := range root.Infrastructure {
+		providers[string(infrastructure.Id)] = infrastructure.Provider
+		entry, ok := catalog[infrastructure.Provider]
+		region := string(infrastructure.Region)
+		if !ok || region == "" || contains(entry.Regions, region) {
+			continue
+		}
+		c.warn(RuleUnknownRegion, pointer("infrastructure", i, "region"), "infrastructure %q: region %q is not a known %s region%s",
+			infrastructure.Id, region, infrastructure.Provider, didYouMean(region, entry.Regions),
+		)
+	}
+
+	for i, node := range root.Nodes {
+		provider, ok := providers[string(node.InfrastructureId)]
+		if !ok {
+			continue
+		}
+		entry, ok := catalog[provider]
+		instanceType := string(node.Properties.InstanceType)
+		if !ok || instanceType == "" {
+			continue
+		}
+		if _, known := entry.InstanceTypes[instanceType]; known {
+			continue
+		}
+		names := make([]string, 0, len(entry.InstanceTypes))
+		for name := range entry.InstanceTypes {
+			names = append(names, name)
+		}
+		c.warn(RuleUnknownInstanceType, pointer("nodes", i, "properties", "instance_type"), "node %q: instance type %q is not a known %s instance type%s",
+			node.Id, instanceType, provider, didYouMean(instanceType, names),
+		)
+	}
+}
+
+// maxSuggestions is the number of near matches suggested for an unknown value.
+const maxSuggestions = 3
+
+// didYouMean suggests the candidates closest to an unknown value, as a sentence appended to an error
+// message. Only candidates a few edits away from the value are suggested.
+func didYouMean(value string, candidates []string) string {
+	threshold := max(2, len(value)/4)
+	best := threshold + 1
+	var matches []string
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(value), strings.ToLower(candidate))
+		switch {
+		case distance < best:
+			best, matches = distance, []string{candidate}
+		case distance == best:
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+
+	sort.Strings(matches)
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	quoted := make([]string, len(matches))
+	for i, match := range matches {
+		quoted[i] = fmt.Sprintf("%q", match)
+	}
+	if len(quoted) == 1 {
+		return fmt.Sprintf(", did you mean %s?", quoted[0])
+	}
+	return fmt.Sprintf(", did you mean %s or %s?", strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1])
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions
+// of adjacent characters turning a into b (optimal string alignment distance).
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
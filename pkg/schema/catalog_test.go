@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const catalogBase = `name: catalog-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: %s}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: %s}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+devcontainers:
+  - {id: web, node_id: node1, source: {url: "https://github.com/example/web.git"}}
+`
+
+func TestDefaultCatalog(t *testing.T) {
+	instanceType, ok := DefaultCatalog().InstanceType(ProviderAws, "t4g.medium")
+	require.True(t, ok)
+	assert.Equal(t, InstanceType{VCPUs: 2, MemoryGiB: 4, Architecture: "arm64"}, instanceType)
+
+	_, ok = DefaultCatalog().InstanceType(ProviderAws, "t3.mirco")
+	assert.False(t, ok)
+}
+
+func TestParseCatalog(t *testing.T) {
+	t.Run("unknown regions and instance types suggest near matches", func(t *testing.T) {
+		errs, err := Validate([]byte(fmt.Sprintf(catalogBase, "eu-centrl-1", "t3.mirco")), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		require.Len(t, errs, 2)
+		assert.Equal(t, &ValidationError{Code: RuleUnknownRegion, Pointer: "/infrastructure/0/region", Line: 3, Column: 40, Severity: SeverityWarning,
+			Message: `infrastructure "aws": region "eu-centrl-1" is not a known aws region, did you mean "eu-central-1"?`}, errs[0])
+		assert.Equal(t, &ValidationError{Code: RuleUnknownInstanceType, Pointer: "/nodes/0/properties/instance_type", Line: 5, Column: 54, Severity: SeverityWarning,
+			Message: `node "node1": instance type "t3.mirco" is not a known aws instance type, did you mean "t3.micro"?`}, errs[1])
+	})
+
+	t.Run("values far from any entry get no suggestion", func(t *testing.T) {
+		errs, err := Validate([]byte(fmt.Sprintf(catalogBase, "moon-base-1", "t3.micro")), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		require.Len(t, errs, 1)
+		assert.Equal(t, `infrastructure "aws": region "moon-base-1" is not a known aws region`, errs[0].Message)
+	})
+
+	t.Run("unknown values do not make the file invalid", func(t *testing.T) {
+		_, err := Parse([]byte(fmt.Sprintf(catalogBase, "eu-central-9", "x9.huge")), WithFormat(FormatYAML))
+		assert.NoError(t, err)
+	})
+
+	t.Run("a user catalog is merged into the embedded one", func(t *testing.T) {
+		catalog, err := ParseCatalog([]byte(`
+aws:
+  regions: [eu-central-3]
+  instance_types:
+    x9.huge: {vcpus: 512, memory_gib: 4096, architecture: x86_64}
+`), FormatYAML)
+		require.NoError(t, err)
+
+		errs, err := Validate([]byte(fmt.Sprintf(catalogBase, "eu-central-3", "x9.huge")), WithFormat(FormatYAML), WithCatalog(catalog))
+		require.NoError(t, err)
+		assert.Empty(t, errs)
+
+		// Built-in regions and instance types are still known
+		errs, err = Validate([]byte(fmt.Sprintf(catalogBase, "eu-central-1", "t3.micro")), WithFormat(FormatYAML), WithCatalog(catalog))
+		require.NoError(t, err)
+		assert.Empty(t, errs)
+
+		// The embedded catalog is left untouched
+		_, ok := DefaultCatalog().InstanceType(ProviderAws, "x9.huge")
+		assert.False(t, ok)
+	})
+
+	t.Run("a user catalog with only instance types keeps the built-in regions", func(t *testing.T) {
+		catalog, err := ParseCatalog([]byte(`{"aws": {"instance_types": {"t3.medium": {"vcpus": 2, "memory_gib": 4, "architecture": "arm64"}}}}`), FormatJSON)
+		require.NoError(t, err)
+
+		merged := DefaultCatalog().Override(catalog)
+		assert.Equal(t, DefaultCatalog()[ProviderAws].Regions, merged[ProviderAws].Regions)
+		instanceType, ok := merged.InstanceType(ProviderAws, "t3.medium")
+		require.True(t, ok)
+		assert.Equal(t, "arm64", instanceType.Architecture)
+		_, ok = merged.InstanceType(ProviderAws, "t4g.medium")
+		assert.True(t, ok)
+	})
+
+	t.Run("invalid catalogs are rejected", func(t *testing.T) {
+		_, err := ParseCatalog([]byte(`{"aws": {}}`), FormatJSON)
+		assert.EqualError(t, err, `catalog of provider "aws" must list regions or instance_types`)
+
+		_, err = ParseCatalog([]byte(`{"aws": {"regions": ["eu-central-1"], "instance_types": {"t3.micro": {"cpus": 2}}}}`), FormatJSON)
+		assert.ErrorContains(t, err, `unknown field "cpus"`)
+	})
+}
+
+func TestDidYouMean(t *testing.T) {
+	candidates := []string{"t3.micro", "t3.small", "t3a.micro", "t2.micro"}
+
+	assert.Equal(t, `, did you mean "t3.micro"?`, didYouMean("t3.mcro", candidates))
+	assert.Equal(t, `, did you mean "t2.micro" or "t3.micro"?`, didYouMean("t4.micro", candidates))
+	assert.Equal(t, `, did you mean "m5.large", "m6.large" or "m7.large"?`, didYouMean("m9.large", []string{"m8.large", "m7.large", "m6.large", "m5.large"}))
+	assert.Equal(t, "", didYouMean("x1e.32xlarge", candidates))
+	assert.Equal(t, 1, editDistance("mirco", "micro"))
+}
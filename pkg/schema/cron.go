@@ -0,0 +1,212 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField describes a field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = [5]cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	// Sunday is both 0 and 7
+	{name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// cronHorizonDays is how far ahead a cron expression is searched for its next time, so that
+// expressions matching only on February 29 are found.
+const cronHorizonDays = 5 * 366
+
+// Cron is a parsed cron expression with the five fields minute, hour, day of month, month and day of week,
+// e.g. "0 19 * * MON-FRI". Fields may be lists of values, ranges and steps, such as "1,15", "8-18/2" or "*/30".
+// Months and days of week may be given by their three-letter English names. Steps are not allowed in days of week.
+type Cron struct {
+	expression string
+	fields     [5]string
+	sets       [5]uint64
+}
+
+// ParseCron parses a cron expression with five fields.
+func ParseCron(expression string) (*Cron, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%q has %d fields, expected 5: minute, hour, day of month, month and day of week", expression, len(fields))
+	}
+
+	c := &Cron{expression: expression}
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", expression, err)
+		}
+		c.fields[i] = strings.ToUpper(field)
+		c.sets[i] = set
+	}
+
+	// Sunday is 0, whether it is written 0 or 7
+	if c.sets[4]&(1<<7) != 0 {
+		c.sets[4] = c.sets[4]&^(1<<7) | 1
+	}
+	if c.restricted(2) && c.restricted(4) {
+		return nil, fmt.Errorf("%q: day of month and day of week cannot both be set, set one of them to *", expression)
+	}
+	return c, nil
+}
+
+// parseCronField parses a field of a cron expression into the set of its values.
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			if spec.name == "day of week" {
+				return 0, fmt.Errorf("steps are not supported in the day of week, list the days instead")
+			}
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q in the %s", stepPart, spec.name)
+			}
+			step = n
+		}
+
+		low, high := spec.min, spec.max
+		switch {
+		case rangePart == "*":
+			if spec.name == "day of week" {
+				high = 6
+			}
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseCronValue(from, spec); err != nil {
+				return 0, err
+			}
+			if high, err = parseCronValue(to, spec); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("range %q of the %s is reversed", rangePart, spec.name)
+			}
+		default:
+			value, err := parseCronValue(rangePart, spec)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			if !hasStep {
+				high = value
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			set |= 1 << value
+		}
+	}
+	return set, nil
+}
+
+// parseCronValue parses a number or a name of a cron field.
+func parseCronValue(value string, spec cronField) (int, error) {
+	for i, name := range spec.names {
+		if strings.EqualFold(value, name) {
+			return i + spec.min, nil
+		}
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < spec.min || n > spec.max {
+		return 0, fmt.Errorf("invalid %s %q, expected %d-%d", spec.name, value, spec.min, spec.max)
+	}
+	return n, nil
+}
+
+// restricted reports whether a field does not match every value.
+func (c *Cron) restricted(field int) bool {
+	return c.fields[field] != "*"
+}
+
+// String returns the expression as written.
+func (c *Cron) String() string {
+	return c.expression
+}
+
+// Next returns the first time after t, in the location of t, matched by the expression. Times skipped
+// when clocks are set forward are matched by the first time after the change, as EventBridge Scheduler does.
+// It returns the zero time if the expression matches no time within five years, e.g. for February 30.
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	year, month, day := t.Date()
+	for offset := 0; offset < cronHorizonDays; offset++ {
+		// Dates are computed in UTC, where every day has 24 hours
+		date := time.Date(year, month, day+offset, 0, 0, 0, 0, time.UTC)
+		if !c.matches(3, int(date.Month())) || !c.matches(2, date.Day()) || !c.matches(4, int(date.Weekday())) {
+			continue
+		}
+		for hour := 0; hour < 24; hour++ {
+			if !c.matches(1, hour) {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if !c.matches(0, minute) {
+					continue
+				}
+				if next := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc); next.After(t) {
+					return next
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (c *Cron) matches(field, value int) bool {
+	return c.sets[field]&(1<<value) != 0
+}
+
+// EventBridge returns the expression in the syntax of EventBridge Scheduler, e.g. "cron(0 19 ? * MON-FRI *)".
+// Days of week are written by their names, as EventBridge numbers them from 1 for Sunday.
+func (c *Cron) EventBridge() string {
+	dayOfMonth, dayOfWeek := c.fields[2], "?"
+	if c.restricted(4) {
+		dayOfMonth, dayOfWeek = "?", c.weekdayNames()
+	}
+	return fmt.Sprintf("cron(%s %s %s %s %s *)", c.fields[0], c.fields[1], dayOfMonth, c.fields[3], dayOfWeek)
+}
+
+// weekdayNames returns the days of week of the expression as names, with runs of days written as ranges.
+func (c *Cron) weekdayNames() string {
+	names := cronFields[4].names
+	var parts []string
+	for day := 0; day < 7; day++ {
+		if !c.matches(4, day) {
+			continue
+		}
+		end := day
+		for end+1 < 7 && c.matches(4, end+1) {
+			end++
+		}
+		switch {
+		case end == day:
+			parts = append(parts, names[day])
+		case end == day+1:
+			parts = append(parts, names[day], names[end])
+		default:
+			parts = append(parts, names[day]+"-"+names[end])
+		}
+		day = end
+	}
+	if len(parts) == 0 {
+		return "?"
+	}
+	return strings.Join(parts, ",")
+}
@@ -8,12 +8,18 @@ const (
 	SshKeySourceSsmParameterStore SshKeySource = "ssm_parameter_store"
 )
 
+// Range of the ports devcontainers can be exposed on.
+const (
+	minPort = 1024
+	maxPort = 65535
+)
+
 type DevcontainerOpenVSCodeServer struct {
-	Port *int `json:"port,omitempty" jsonschema:"minimum=1024,maximum=65535" jsonschema_description:"TCP port used to expose the OpenVSCode Server interface. If not specified, an available port will be selected automatically. Must be omitted if DNS is configured at the node level."`
+	Port *int `json:"port,omitempty" jsonschema:"minimum=1024,maximum=65535" jsonschema_description:"TCP port used to expose the OpenVSCode Server interface. If not specified, the first port from 8000 not used on the node is assigned. Must be omitted if DNS is configured at the node level. Must be unique on the node."`
 }
 
 type DevcontainerSSH struct {
-	Port         *int          `json:"port,omitempty" jsonschema:"minimum=1024,maximum=65535" jsonschema_description:"TCP port used for remote SSH access to the devcontainer. If not specified, an available port will be selected automatically."`
+	Port         *int          `json:"port,omitempty" jsonschema:"minimum=1024,maximum=65535" jsonschema_description:"TCP port used for remote SSH access to the devcontainer. If not specified, the first port from 2222 not used on the node is assigned. Must be unique on the node."`
 	PublicSshKey TrimmedString `json:"public_ssh_key,omitempty" jsonschema_description:"Path to the local public SSH key used for authentication. If omitted, the public SSH key configured at the node level will be used."`
 }
 
@@ -28,15 +34,43 @@ type DevcontainerSourceSSHKey struct {
 }
 
 type DevcontainerSource struct {
-	URL              TrimmedString             `json:"url" jsonschema:"required,format=uri,minLength=1" jsonschema_description:"Git repository URL containing the devcontainer definition. For SSH URLs (starting with 'ssh://' or 'git@'), an SSH key must be provided."`
-	Branch           TrimmedString             `json:"branch,omitempty" jsonschema_description:"Git branch to checkout. If not specified, the repository's default branch will be used."`
+	URL              TrimmedString             `json:"url,omitempty" jsonschema:"minLength=1" jsonschema_description:"Git repository URL containing the devcontainer definition: 'https://', 'ssh://', scp-like ('git@github.com:org/repo.git'), 'git://' or 'file://'. HTTPS URLs must not contain credentials. For SSH URLs, an SSH key must be provided. Required unless set in 'defaults.devcontainer'."`
+	Branch           TrimmedString             `json:"branch,omitempty" jsonschema_description:"Git branch to checkout. If no branch, tag or commit is specified, the repository's default branch will be used. Cannot be used with 'tag' or 'commit'."`
+	Tag              TrimmedString             `json:"tag,omitempty" jsonschema:"minLength=1" jsonschema_description:"Git tag to checkout, pinning the devcontainer to a release. Cannot be used with 'branch' or 'commit'."`
+	Commit           TrimmedString             `json:"commit,omitempty" jsonschema_description:"Git commit to checkout, as a full or abbreviated (at least 7 characters) lowercase hexadecimal SHA, for reproducible environments. Cannot be used with 'branch' or 'tag'."`
 	DevcontainerPath TrimmedString             `json:"devcontainer_path,omitempty" jsonschema_description:"Relative path to the devcontainer definition within the repository. If not specified, the root directory will be used."`
 	SshKey           *DevcontainerSourceSSHKey `json:"ssh_key,omitempty" jsonschema_description:"SSH key configuration for cloning from private Git repositories. Required for SSH URLs, must be omitted for HTTPS URLs."`
 }
 
+// Revision returns the kind and name of the revision a source checks out, e.g. "tag" and "v1.2.0".
+// When several revisions are set, which validation reports, the most specific one is returned.
+// Both are empty when the default branch of the repository is used.
+func (s *DevcontainerSource) Revision() (string, string) {
+	switch {
+	case s == nil:
+		return "", ""
+	case s.Commit != "":
+		return "commit", string(s.Commit)
+	case s.Tag != "":
+		return "tag", string(s.Tag)
+	case s.Branch != "":
+		return "branch", string(s.Branch)
+	}
+	return "", ""
+}
+
 type Devcontainer struct {
-	Id           TrimmedString             `json:"id" jsonschema:"required,minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Unique identifier of this devcontainer within the cluster."`
-	NodeId       TrimmedString             `json:"node_id" jsonschema:"required,minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Identifier of the node that will host this devcontainer (must match an entry in the top‑level nodes list)."`
-	Source       *DevcontainerSource       `json:"source" jsonschema:"required" jsonschema_description:"Reference to the source location containing the devcontainer definition and related files."`
-	RemoteAccess *DevcontainerRemoteAccess `json:"remote_access,omitempty" jsonschema_description:"Configuration for accessing the devcontainer remotely via SSH or a web-based IDE. OpenVSCode Server is enabled by default."`
+	Id                     TrimmedString             `json:"id" jsonschema:"required,minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Unique identifier of this devcontainer within the cluster."`
+	NodeId                 TrimmedString             `json:"node_id,omitempty" jsonschema:"minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Identifier of the node that will host this devcontainer (must match an entry in the top‑level nodes list). Required unless set in 'defaults.devcontainer'."`
+	Source                 *DevcontainerSource       `json:"source,omitempty" jsonschema_description:"Reference to the source location containing the devcontainer definition and related files. Cannot be used with 'image'."`
+	Image                  *DevcontainerImage        `json:"image,omitempty" jsonschema_description:"Prebuilt container image run as the devcontainer, instead of building it from 'source'. Cannot be used with 'source'."`
+	AdditionalRepositories []*DevcontainerRepository `json:"additional_repositories,omitempty" jsonschema_description:"Other repositories cloned into the workspace of the devcontainer, each into its own directory, so the workspace opens with all of them checked out."`
+	Env                    map[string]TrimmedString  `json:"env,omitempty" jsonschema_description:"Environment variables of the devcontainer, mapped to their value, e.g. API endpoints. Values are written to the generated Terraform configuration: set tokens and passwords in 'secrets' instead."`
+	Secrets                []*DevcontainerSecret     `json:"secrets,omitempty" jsonschema_description:"Environment variables of the devcontainer whose values are read from Secrets Manager or SSM Parameter Store when the devcontainer starts. The values never appear in the Terraform configuration or state."`
+	Tags                   map[string]TrimmedString  `json:"tags,omitempty" jsonschema_description:"Tags of the resources of this devcontainer, e.g. for cost allocation. Merged with the tags of the levels above, whose values are overridden. Keys are at most 128 characters long and cannot start with 'aws:', values at most 256 characters."`
+	ExpiresAt              TrimmedString             `json:"expires_at,omitempty" jsonschema:"minLength=1" jsonschema_description:"Time this devcontainer expires, in RFC 3339 format, e.g. '2024-06-30T18:00:00Z'. Expired devcontainers are removed by 'denvclustr reap', and the cluster is destroyed once all of them expired. Cannot be used with 'ttl'."`
+	TTL                    TrimmedString             `json:"ttl,omitempty" jsonschema:"minLength=1" jsonschema_description:"Time to live of this devcontainer from its deployment, e.g. '2h' or '1d12h'. Expired devcontainers are removed by 'denvclustr reap'. Cannot be used with 'expires_at'."`
+	RemoteAccess           *DevcontainerRemoteAccess `json:"remote_access,omitempty" jsonschema_description:"Configuration for accessing the devcontainer remotely via SSH or a web-based IDE. OpenVSCode Server is enabled by default."`
+	Replicas               *int                      `json:"replicas,omitempty" jsonschema:"minimum=1" jsonschema_description:"Number of identical devcontainers deployed from this definition, with ids suffixed by their number, e.g. 'api-dev-01'. Ports set in 'remote_access' are incremented for each replica. Cannot be used with 'for_each'."`
+	ForEach                []TrimmedString           `json:"for_each,omitempty" jsonschema:"minItems=1,uniqueItems=true" jsonschema_description:"Names of identical devcontainers deployed from this definition, with ids suffixed by their name, e.g. 'api-dev-alice'. Ports set in 'remote_access' are incremented for each devcontainer. Cannot be used with 'replicas'."`
 }
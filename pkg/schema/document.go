@@ -0,0 +1,397 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is a decoded denvclustr file. Every supported format is decoded into
+// a YAML node tree, which keeps the key order and the source position of every value.
+type document struct {
+	format Format
+	root   *yaml.Node
+	// origins records the file of the nodes merged from overlays.
+	origins map[*yaml.Node]string
+}
+
+// decodeDocument decodes data of the given format. Syntax errors are reported
+// as a *ValidationError carrying the position of the error.
+func decodeDocument(data []byte, format Format) (*document, error) {
+	var root *yaml.Node
+	switch format {
+	case FormatJSON, FormatJSONC:
+		if format == FormatJSONC {
+			standardized, err := standardizeJSONC(data)
+			if err != nil {
+				return nil, newSyntaxError(data, format, err)
+			}
+			data = standardized
+		}
+
+		var probe any
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return nil, newSyntaxError(data, format, err)
+		}
+
+		node, err := newJSONNodeBuilder(data).build()
+		if err != nil {
+			return nil, newSyntaxError(data, format, err)
+		}
+		root = node
+	case FormatYAML:
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, newSyntaxError(data, format, err)
+		}
+		if node.Kind != yaml.DocumentNode || len(node.Content) == 0 {
+			return nil, newSyntaxError(data, format, fmt.Errorf("document is empty"))
+		}
+		root = node.Content[0]
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	return &document{format: format, root: root}, nil
+}
+
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// newSyntaxError converts a decoding error into a ValidationError,
+// extracting the error position where the decoder provides one.
+func newSyntaxError(data []byte, format Format, err error) *ValidationError {
+	syntaxErr := &ValidationError{
+		Code:     RuleSyntax,
+		Severity: SeverityError,
+		Message:  fmt.Sprintf("decode %s document: %v", format, err),
+	}
+
+	var jsonErr *json.SyntaxError
+	var jsoncErr *jsoncSyntaxError
+	var builderErr *jsonNodeBuilderError
+	switch {
+	case errors.As(err, &jsonErr):
+		// The offset points right after the offending character
+		syntaxErr.Line, syntaxErr.Column = positionAt(data, max(int(jsonErr.Offset)-1, 0))
+	case errors.As(err, &jsoncErr):
+		syntaxErr.Line, syntaxErr.Column = positionAt(data, jsoncErr.offset)
+	case errors.As(err, &builderErr):
+		syntaxErr.Line, syntaxErr.Column = positionAt(data, builderErr.offset)
+	default:
+		if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			syntaxErr.Line, _ = strconv.Atoi(match[1])
+			syntaxErr.Column = 1
+		}
+	}
+	return syntaxErr
+}
+
+// locate returns the line and column of the value addressed by a JSON pointer, along with
+// the overlay file it comes from, if any. Object members are located by their key. When the
+// pointer addresses a value that does not exist, the position of its closest existing ancestor is returned.
+func (d *document) locate(p string) (string, int, int) {
+	position := d.root
+	node := d.root
+	for _, token := range splitPointer(p) {
+		key, value := childNode(node, token)
+		if value == nil {
+			break
+		}
+		if key != nil {
+			position = key
+		} else {
+			position = value
+		}
+		node = value
+	}
+	return d.origins[position], position.Line, position.Column
+}
+
+// locateErrors fills in the position of every error that has none yet.
+func (d *document) locateErrors(errs ValidationErrors) {
+	for _, err := range errs {
+		if err.Line == 0 {
+			err.File, err.Line, err.Column = d.locate(err.Pointer)
+		}
+	}
+}
+
+// origin returns the file the value addressed by a JSON pointer comes from, empty for the parsed file itself.
+func (d *document) origin(p string) string {
+	file, _, _ := d.locate(p)
+	return file
+}
+
+// childNode returns the member of a mapping node with the given key, along with its key node,
+// or the element of a sequence node at the given index.
+func childNode(node *yaml.Node, token string) (*yaml.Node, *yaml.Node) {
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == token {
+				return node.Content[i], resolveAlias(node.Content[i+1])
+			}
+		}
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(token)
+		if err == nil && index >= 0 && index < len(node.Content) {
+			return nil, resolveAlias(node.Content[index])
+		}
+	}
+	return nil, nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+// value converts the document into the generic representation produced by json.Unmarshal.
+func (d *document) value() (any, error) {
+	data, err := d.marshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("convert document: %w", err)
+	}
+	return value, nil
+}
+
+// marshalJSON encodes the document as standard JSON.
+func (d *document) marshalJSON() ([]byte, error) {
+	var value any
+	if err := d.root.Decode(&value); err != nil {
+		return nil, fmt.Errorf("convert document: %w", err)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("convert document: %w", err)
+	}
+	return data, nil
+}
+
+// jsonNodeBuilder turns syntactically valid JSON into a YAML node tree,
+// recording the line and column of every value.
+type jsonNodeBuilder struct {
+	data       []byte
+	offset     int
+	lineStarts []int
+}
+
+func newJSONNodeBuilder(data []byte) *jsonNodeBuilder {
+	lineStarts := []int{0}
+	for i, c := range data {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return &jsonNodeBuilder{data: data, lineStarts: lineStarts}
+}
+
+func (b *jsonNodeBuilder) build() (*yaml.Node, error) {
+	node, err := b.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	b.skipWhitespace()
+	if b.offset != len(b.data) {
+		return nil, b.errorf("unexpected data after top-level value")
+	}
+	return node, nil
+}
+
+func (b *jsonNodeBuilder) parseValue() (*yaml.Node, error) {
+	b.skipWhitespace()
+	if b.offset >= len(b.data) {
+		return nil, b.errorf("unexpected end of input")
+	}
+
+	line, column := b.position(b.offset)
+	switch c := b.data[b.offset]; {
+	case c == '{':
+		return b.parseObject(line, column)
+	case c == '[':
+		return b.parseArray(line, column)
+	case c == '"':
+		return b.parseString(line, column)
+	case c == 't':
+		return b.parseLiteral("true", "!!bool", line, column)
+	case c == 'f':
+		return b.parseLiteral("false", "!!bool", line, column)
+	case c == 'n':
+		return b.parseLiteral("null", "!!null", line, column)
+	default:
+		return b.parseNumber(line, column)
+	}
+}
+
+func (b *jsonNodeBuilder) parseObject(line, column int) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: line, Column: column}
+	b.offset++
+
+	b.skipWhitespace()
+	if b.peek() == '}' {
+		b.offset++
+		return node, nil
+	}
+
+	for {
+		b.skipWhitespace()
+		keyLine, keyColumn := b.position(b.offset)
+		if b.peek() != '"' {
+			return nil, b.errorf("expected object key")
+		}
+		key, err := b.parseString(keyLine, keyColumn)
+		if err != nil {
+			return nil, err
+		}
+
+		b.skipWhitespace()
+		if b.peek() != ':' {
+			return nil, b.errorf("expected ':' after object key")
+		}
+		b.offset++
+
+		value, err := b.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, key, value)
+
+		b.skipWhitespace()
+		switch b.peek() {
+		case ',':
+			b.offset++
+		case '}':
+			b.offset++
+			return node, nil
+		default:
+			return nil, b.errorf("expected ',' or '}' in object")
+		}
+	}
+}
+
+func (b *jsonNodeBuilder) parseArray(line, column int) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: line, Column: column}
+	b.offset++
+
+	b.skipWhitespace()
+	if b.peek() == ']' {
+		b.offset++
+		return node, nil
+	}
+
+	for {
+		value, err := b.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, value)
+
+		b.skipWhitespace()
+		switch b.peek() {
+		case ',':
+			b.offset++
+		case ']':
+			b.offset++
+			return node, nil
+		default:
+			return nil, b.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (b *jsonNodeBuilder) parseString(line, column int) (*yaml.Node, error) {
+	end := skipJSONString(b.data, b.offset)
+	if end >= len(b.data) {
+		return nil, b.errorf("unterminated string")
+	}
+
+	var value string
+	if err := json.Unmarshal(b.data[b.offset:end+1], &value); err != nil {
+		return nil, b.errorf("invalid string: %v", err)
+	}
+	b.offset = end + 1
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Line: line, Column: column}, nil
+}
+
+func (b *jsonNodeBuilder) parseLiteral(literal, tag string, line, column int) (*yaml.Node, error) {
+	if len(b.data)-b.offset < len(literal) || string(b.data[b.offset:b.offset+len(literal)]) != literal {
+		return nil, b.errorf("invalid literal")
+	}
+	b.offset += len(literal)
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: literal, Line: line, Column: column}, nil
+}
+
+func (b *jsonNodeBuilder) parseNumber(line, column int) (*yaml.Node, error) {
+	start := b.offset
+	tag := "!!int"
+	for b.offset < len(b.data) {
+		c := b.data[b.offset]
+		if c == '.' || c == 'e' || c == 'E' {
+			tag = "!!float"
+		} else if !(c == '-' || c == '+' || (c >= '0' && c <= '9')) {
+			break
+		}
+		b.offset++
+	}
+	if b.offset == start {
+		return nil, b.errorf("invalid character %q", b.data[start])
+	}
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(b.data[start:b.offset]), Line: line, Column: column}, nil
+}
+
+func (b *jsonNodeBuilder) skipWhitespace() {
+	for b.offset < len(b.data) && isJSONWhitespace(b.data[b.offset]) {
+		b.offset++
+	}
+}
+
+func (b *jsonNodeBuilder) peek() byte {
+	if b.offset >= len(b.data) {
+		return 0
+	}
+	return b.data[b.offset]
+}
+
+// position converts a byte offset into a 1-based line and column.
+func (b *jsonNodeBuilder) position(offset int) (int, int) {
+	line := sort.Search(len(b.lineStarts), func(i int) bool { return b.lineStarts[i] > offset })
+	lineStart := b.lineStarts[line-1]
+	return line, utf8.RuneCount(b.data[lineStart:offset]) + 1
+}
+
+func (b *jsonNodeBuilder) errorf(format string, args ...any) error {
+	return &jsonNodeBuilderError{msg: fmt.Sprintf(format, args...), offset: min(b.offset, len(b.data))}
+}
+
+type jsonNodeBuilderError struct {
+	msg    string
+	offset int
+}
+
+func (e *jsonNodeBuilderError) Error() string { return e.msg }
+
+// positionAt converts a byte offset of data into a 1-based line and column.
+func positionAt(data []byte, offset int) (int, int) {
+	offset = min(offset, len(data))
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	return bytes.Count(data[:offset], []byte("\n")) + 1, utf8.RuneCount(data[lineStart:offset]) + 1
+}
@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Marshal encodes a DenvclustrRoot in the given format. JSON and JSONC are both written as plain JSON.
+func Marshal(root *DenvclustrRoot, format Format) ([]byte, error) {
+	doc, err := newModelDocument(root, format)
+	if err != nil {
+		return nil, err
+	}
+	return doc.encode()
+}
+
+// MarshalEffective encodes a model returned by Parse as a standalone denvclustr file, e.g. to print
+// the effective configuration. The variables block is left out, since variables are already resolved,
+// and literal "${" sequences are escaped, so the file reads back as the same model.
+func MarshalEffective(root *DenvclustrRoot, format Format) ([]byte, error) {
+	effective := *root
+	effective.Variables = nil
+	doc, err := newModelDocument(&effective, format)
+	if err != nil {
+		return nil, err
+	}
+	escapeInterpolations(doc.root)
+	return doc.encode()
+}
+
+func newModelDocument(root *DenvclustrRoot, format Format) (*document, error) {
+	data, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("marshal denvclustr file: %w", err)
+	}
+	node, err := newJSONNodeBuilder(data).build()
+	if err != nil {
+		return nil, fmt.Errorf("marshal denvclustr file: %w", err)
+	}
+	return &document{format: format, root: node}, nil
+}
+
+// escapeInterpolations escapes "${" as "$${" in the string values of a node tree.
+func escapeInterpolations(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			escapeInterpolations(node.Content[i])
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			escapeInterpolations(item)
+		}
+	case yaml.ScalarNode:
+		if node.Tag == "!!str" {
+			node.Value = strings.ReplaceAll(node.Value, "${", "$${")
+		}
+	}
+}
+
+// encode writes the document back in its original format, keeping the key order.
+// JSONC documents are written as plain JSON since comments are not kept in the node tree.
+func (d *document) encode() ([]byte, error) {
+	switch d.format {
+	case FormatYAML:
+		return encodeYAMLNode(d.root)
+	case FormatJSON, FormatJSONC:
+		var buf bytes.Buffer
+		if err := encodeJSONNode(&buf, d.root, 0); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", d.format)
+	}
+}
+
+func encodeYAMLNode(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("encode yaml document: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encode yaml document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeJSONNode writes a node tree as JSON indented with two spaces.
+func encodeJSONNode(buf *bytes.Buffer, node *yaml.Node, depth int) error {
+	node = resolveAlias(node)
+	indent := strings.Repeat("  ", depth+1)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, err := marshalJSONValue(node.Content[i].Value)
+			if err != nil {
+				return fmt.Errorf("encode json document: %w", err)
+			}
+			buf.WriteString(indent)
+			buf.Write(key)
+			buf.WriteString(": ")
+			if err := encodeJSONNode(buf, node.Content[i+1], depth+1); err != nil {
+				return err
+			}
+			if i+2 < len(node.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent[2:])
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, item := range node.Content {
+			buf.WriteString(indent)
+			if err := encodeJSONNode(buf, item, depth+1); err != nil {
+				return err
+			}
+			if i+1 < len(node.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent[2:])
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		// Keep numbers as written when they are valid JSON
+		if (node.Tag == "!!int" || node.Tag == "!!float") && json.Valid([]byte(node.Value)) {
+			buf.WriteString(node.Value)
+			return nil
+		}
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return fmt.Errorf("encode json document: %w", err)
+		}
+		data, err := marshalJSONValue(value)
+		if err != nil {
+			return fmt.Errorf("encode json document: %w", err)
+		}
+		buf.Write(data)
+	default:
+		return fmt.Errorf("encode json document: unsupported node kind %d", node.Kind)
+	}
+	return nil
+}
+
+// marshalJSONValue encodes a value as JSON without escaping HTML characters.
+func marshalJSONValue(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
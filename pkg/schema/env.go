@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envNamePattern matches the name of an environment variable.
+var envNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// secretNameHints are parts of environment variable names suggesting their value is a secret.
+var secretNameHints = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "API_KEY", "PRIVATE_KEY", "CREDENTIAL"}
+
+// DevcontainerSecret is an environment variable of a devcontainer whose value is read from a secret backend.
+// Only the reference is written to the generated Terraform configuration: the value is read on the node,
+// so it never appears in the configuration or in the Terraform state.
+type DevcontainerSecret struct {
+	Name      TrimmedString `json:"name" jsonschema:"required,minLength=1,pattern=^[a-zA-Z_][a-zA-Z0-9_]*$" jsonschema_description:"Name of the environment variable set to the value of the secret."`
+	Source    SshKeySource  `json:"source" jsonschema:"required,enum=secrets_manager,enum=ssm_parameter_store" jsonschema_description:"Secret backend service where the value is stored. Must be either 'secrets_manager' or 'ssm_parameter_store'."`
+	Reference TrimmedString `json:"reference" jsonschema:"required,minLength=1" jsonschema_description:"Name of the secret in Secrets Manager, or of the parameter in SSM Parameter Store."`
+}
+
+// validateEnvironment checks that the devcontainer at index i sets each environment variable once,
+// and warns about literal values that look like secrets.
+func validateEnvironment(devcontainer *Devcontainer, i int, c *collector) {
+	names := make([]string, 0, len(devcontainer.Env))
+	for name := range devcontainer.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if looksLikeSecret(name) && devcontainer.Env[name] != "" {
+			c.warn(RuleSecretInEnv, pointer("devcontainers", i, "env", name),
+				"%s: env %q looks like a secret, set it in secrets so its value is not written to the Terraform configuration", c.devcontainer(i, devcontainer), name,
+			)
+		}
+	}
+
+	seen := make(map[string]int)
+	for j, secret := range devcontainer.Secrets {
+		if secret == nil || secret.Name == "" {
+			continue
+		}
+		name := string(secret.Name)
+		if _, ok := devcontainer.Env[name]; ok {
+			c.add(RuleDuplicateEnv, pointer("devcontainers", i, "secrets", j, "name"), "%s: %s is set by both env and secrets", c.devcontainer(i, devcontainer), name)
+			continue
+		}
+		if first, ok := seen[name]; ok {
+			c.add(RuleDuplicateEnv, pointer("devcontainers", i, "secrets", j, "name"), "%s: %s is already set by secrets[%d]", c.devcontainer(i, devcontainer), name, first)
+			continue
+		}
+		seen[name] = j
+	}
+}
+
+func looksLikeSecret(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, hint := range secretNameHints {
+		if strings.Contains(upper, hint) {
+			return true
+		}
+	}
+	return false
+}
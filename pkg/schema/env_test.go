@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEnvironment(t *testing.T) {
+	const base = `name: api-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+devcontainers:
+  - id: api
+    node_id: node1
+    source: {url: "https://github.com/example/api.git"}
+    env:
+      API_URL: https://api.staging.example.com
+    secrets:
+      - {name: API_TOKEN, source: secrets_manager, reference: staging/api-token}
+      - %s
+`
+
+	t.Run("valid environment", func(t *testing.T) {
+		root, err := Parse([]byte(fmt.Sprintf(base,
+			`{name: DATABASE_PASSWORD, source: ssm_parameter_store, reference: /staging/database/password}`,
+		)), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		devcontainer := root.Devcontainers[0]
+		assert.Equal(t, map[string]TrimmedString{"API_URL": "https://api.staging.example.com"}, devcontainer.Env)
+		require.Len(t, devcontainer.Secrets, 2)
+		assert.Equal(t, &DevcontainerSecret{
+			Name:      "DATABASE_PASSWORD",
+			Source:    SshKeySourceSsmParameterStore,
+			Reference: "/staging/database/password",
+		}, devcontainer.Secrets[1])
+	})
+
+	t.Run("secret in env", func(t *testing.T) {
+		errs, err := Validate([]byte(`name: api-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+devcontainers:
+  - id: api
+    node_id: node1
+    source: {url: "https://github.com/example/api.git"}
+    env:
+      GITHUB_TOKEN: ghp_0123456789
+`), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		require.Len(t, errs, 1)
+		assert.Equal(t, SeverityWarning, errs[0].Severity)
+		assert.Equal(t, RuleSecretInEnv, errs[0].Code)
+		assert.Equal(t, "/devcontainers/0/env/GITHUB_TOKEN", errs[0].Pointer)
+	})
+
+	tests := []struct {
+		name    string
+		secret  string
+		code    string
+		pointer string
+		message string
+	}{
+		{"name set by env", `{name: API_URL, source: secrets_manager, reference: staging/api-url}`, RuleDuplicateEnv,
+			"/devcontainers/0/secrets/1/name", `devcontainer "api": API_URL is set by both env and secrets`},
+		{"name set by another secret", `{name: API_TOKEN, source: ssm_parameter_store, reference: /staging/api-token}`, RuleDuplicateEnv,
+			"/devcontainers/0/secrets/1/name", `devcontainer "api": API_TOKEN is already set by secrets[0]`},
+		{"invalid name", `{name: API-TOKEN, source: secrets_manager, reference: staging/api-token}`, RuleSchemaPrefix + "pattern",
+			"/devcontainers/0/secrets/1/name", "'API-TOKEN' does not match pattern '^[a-zA-Z_][a-zA-Z0-9_]*$'"},
+		{"unknown backend", `{name: DATABASE_PASSWORD, source: vault, reference: database}`, RuleSchemaPrefix + "enum",
+			"/devcontainers/0/secrets/1/source", "value must be one of 'secrets_manager', 'ssm_parameter_store'"},
+		{"missing reference", `{name: DATABASE_PASSWORD, source: secrets_manager}`, RuleSchemaPrefix + "required",
+			"/devcontainers/0/secrets/1", "missing property 'reference'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate([]byte(fmt.Sprintf(base, tt.secret)), WithFormat(FormatYAML))
+			require.NoError(t, err)
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.code, errs[0].Code)
+			assert.Equal(t, tt.pointer, errs[0].Pointer)
+			assert.Equal(t, tt.message, errs[0].Message)
+		})
+	}
+}
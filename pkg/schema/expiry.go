@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ttlPattern matches a time to live: days, hours, minutes and seconds, each optional and in this order.
+var ttlPattern = regexp.MustCompile(`^(?:(\d+)d)?(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s)?$`)
+
+// ttlUnits are the units of the groups of ttlPattern.
+var ttlUnits = []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+// ParseTTL parses a time to live, written as days, hours, minutes and seconds in this order, each optional,
+// e.g. "7d", "36h", "90m" or "1d12h". Signs and fractions are not allowed, and the total must be positive.
+func ParseTTL(ttl string) (time.Duration, error) {
+	match := ttlPattern.FindStringSubmatch(ttl)
+	if match == nil || ttl == "" {
+		return 0, fmt.Errorf("invalid ttl %q, expected a duration such as 7d, 36h or 90m", ttl)
+	}
+
+	var total time.Duration
+	for i, unit := range ttlUnits {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(match[i+1], 10, 64)
+		if err != nil || time.Duration(n) > (math.MaxInt64-total)/unit {
+			return 0, fmt.Errorf("ttl %q is too long", ttl)
+		}
+		total += time.Duration(n) * unit
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("ttl %q must be positive", ttl)
+	}
+	return total, nil
+}
+
+// expiry returns when a cluster or devcontainer deployed at the given time expires,
+// from its expires_at or ttl. It returns the zero time if neither is set.
+func expiry(expiresAt, ttl TrimmedString, deployedAt time.Time) (time.Time, error) {
+	switch {
+	case expiresAt != "":
+		t, err := time.Parse(time.RFC3339, string(expiresAt))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid expires_at %q, expected an RFC 3339 time such as 2024-06-30T18:00:00Z", expiresAt)
+		}
+		return t, nil
+	case ttl != "":
+		duration, err := ParseTTL(string(ttl))
+		if err != nil {
+			return time.Time{}, err
+		}
+		return deployedAt.Add(duration), nil
+	}
+	return time.Time{}, nil
+}
+
+// Expiry returns when the cluster expires if it is deployed at the given time, or the zero time if it never expires.
+func (r *DenvclustrRoot) Expiry(deployedAt time.Time) (time.Time, error) {
+	return expiry(r.ExpiresAt, r.TTL, deployedAt)
+}
+
+// Expiry returns when the devcontainer expires if it is deployed at the given time, or the zero time if it never expires.
+func (d *Devcontainer) Expiry(deployedAt time.Time) (time.Time, error) {
+	return expiry(d.ExpiresAt, d.TTL, deployedAt)
+}
+
+// validateExpiries checks the expires_at and ttl of the cluster and its devcontainers. Only one of them
+// may be set. Expiries in the past and devcontainers outliving the cluster are reported as warnings,
+// as of a deployment now.
+func validateExpiries(root *DenvclustrRoot, c *collector) {
+	now := time.Now()
+
+	clusterExpiry, _ := validateExpiry(c, pointer(), "cluster", root.ExpiresAt, root.TTL, now)
+	for i, devcontainer := range root.Devcontainers {
+		owner := c.devcontainer(i, devcontainer)
+		devcontainerExpiry, ok := validateExpiry(c, pointer("devcontainers", i), owner, devcontainer.ExpiresAt, devcontainer.TTL, now)
+		if ok && !clusterExpiry.IsZero() && devcontainerExpiry.After(clusterExpiry) {
+			c.warn(RuleInvalidExpiry, pointer("devcontainers", i), "%s: expires after the cluster, it is destroyed with the cluster on %s", owner, clusterExpiry.Format(time.RFC3339))
+		}
+	}
+}
+
+// validateExpiry checks the expires_at and ttl of the object at pointer p and returns its expiry,
+// and whether it is valid and set.
+func validateExpiry(c *collector, p, owner string, expiresAt, ttl TrimmedString, now time.Time) (time.Time, bool) {
+	if expiresAt != "" && ttl != "" {
+		c.add(RuleConflictingFields, p+pointer("ttl"), "%s: expires_at and ttl cannot both be set", owner)
+		return time.Time{}, false
+	}
+	field := "expires_at"
+	if ttl != "" {
+		field = "ttl"
+	}
+
+	t, err := expiry(expiresAt, ttl, now)
+	switch {
+	case err != nil:
+		c.add(RuleInvalidExpiry, p+pointer(field), "%s: %v", owner, err)
+		return time.Time{}, false
+	case t.IsZero():
+		return time.Time{}, false
+	case !t.After(now):
+		c.warn(RuleInvalidExpiry, p+pointer(field), "%s: already expired on %s, it is destroyed by the next 'denvclustr reap'", owner, t.Format(time.RFC3339))
+	}
+	return t, true
+}
@@ -0,0 +1,105 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		ttl      string
+		duration time.Duration
+	}{
+		{"90m", 90 * time.Minute},
+		{"36h", 36 * time.Hour},
+		{"7d", 7 * 24 * time.Hour},
+		{"1d12h", 36 * time.Hour},
+		{"2h30m", 150 * time.Minute},
+		{"0d45s", 45 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ttl, func(t *testing.T) {
+			duration, err := ParseTTL(tt.ttl)
+			require.NoError(t, err)
+			assert.Equal(t, tt.duration, duration)
+		})
+	}
+
+	for _, ttl := range []string{"", "0h", "0d0h", "-2h", "+2h", "d", "1.5d", "1.5h", "2 days", "1d-48h", "1d-5h", "0d-1h", "-1d", "12h1d", "99999999999d"} {
+		t.Run(fmt.Sprintf("invalid %q", ttl), func(t *testing.T) {
+			_, err := ParseTTL(ttl)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateExpiry(t *testing.T) {
+	const base = `name: interview-cluster
+%s
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}%s}
+`
+
+	t.Run("expiry", func(t *testing.T) {
+		root, err := Parse([]byte(fmt.Sprintf(base, `ttl: 7d`, `, expires_at: "2999-06-30T18:00:00+02:00"`)), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		deployedAt := time.Date(2024, time.June, 1, 9, 0, 0, 0, time.UTC)
+		expiry, err := root.Expiry(deployedAt)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, time.June, 8, 9, 0, 0, 0, time.UTC), expiry)
+
+		expiry, err = root.Devcontainers[0].Expiry(deployedAt)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2999, time.June, 30, 16, 0, 0, 0, time.UTC), expiry.UTC())
+	})
+
+	t.Run("no expiry", func(t *testing.T) {
+		root, err := Parse([]byte(fmt.Sprintf(base, "", "")), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		expiry, err := root.Expiry(time.Now())
+		require.NoError(t, err)
+		assert.True(t, expiry.IsZero())
+	})
+
+	tests := []struct {
+		name         string
+		root         string
+		devcontainer string
+		severity     Severity
+		code         string
+		pointer      string
+		message      string
+	}{
+		{"both set", "expires_at: \"2999-01-01T00:00:00Z\"\nttl: 8h", "", SeverityError, RuleConflictingFields,
+			"/ttl", "cluster: expires_at and ttl cannot both be set"},
+		{"invalid time", `expires_at: "2999-01-01 00:00"`, "", SeverityError, RuleInvalidExpiry,
+			"/expires_at", `cluster: invalid expires_at "2999-01-01 00:00", expected an RFC 3339 time such as 2024-06-30T18:00:00Z`},
+		{"invalid ttl", "", `, ttl: 2w`, SeverityError, RuleInvalidExpiry,
+			"/devcontainers/0/ttl", `devcontainer "api": invalid ttl "2w", expected a duration such as 7d, 36h or 90m`},
+		{"already expired", `expires_at: "2000-01-01T00:00:00Z"`, "", SeverityWarning, RuleInvalidExpiry,
+			"/expires_at", "cluster: already expired on 2000-01-01T00:00:00Z, it is destroyed by the next 'denvclustr reap'"},
+		{"outlives the cluster", `expires_at: "2998-01-01T00:00:00Z"`, `, expires_at: "2999-01-01T00:00:00Z"`, SeverityWarning, RuleInvalidExpiry,
+			"/devcontainers/0", `devcontainer "api": expires after the cluster, it is destroyed with the cluster on 2998-01-01T00:00:00Z`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate([]byte(fmt.Sprintf(base, tt.root, tt.devcontainer)), WithFormat(FormatYAML))
+			require.NoError(t, err)
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.severity, errs[0].Severity)
+			assert.Equal(t, tt.code, errs[0].Code)
+			assert.Equal(t, tt.pointer, errs[0].Pointer)
+			assert.Equal(t, tt.message, errs[0].Message)
+		})
+	}
+}
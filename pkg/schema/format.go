@@ -0,0 +1,48 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// Enum of supported denvclustr file formats.
+type Format string
+
+const (
+	FormatJSON  Format = "json"
+	FormatJSONC Format = "jsonc"
+	FormatYAML  Format = "yaml"
+)
+
+// DefaultFileNames lists the file names looked up when no denvclustr file is given explicitly.
+var DefaultFileNames = []string{"denvclustr.json", "denvclustr.jsonc", "denvclustr.yaml", "denvclustr.yml"}
+
+// DetectFormat returns the format of a denvclustr file based on its extension.
+// Files with an unknown extension are recognized by their content.
+func DetectFormat(filename string, data []byte) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return FormatJSON
+	case ".jsonc":
+		return FormatJSONC
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return detectContentFormat(data)
+}
+
+// detectContentFormat guesses the format from the first significant characters:
+// strict JSON is kept as JSON, anything else starting like JSON is treated as JSONC
+// and the rest as YAML.
+func detectContentFormat(data []byte) Format {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if json.Valid(trimmed) {
+		return FormatJSON
+	}
+	if bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("//")) || bytes.HasPrefix(trimmed, []byte("/*")) {
+		return FormatJSONC
+	}
+	return FormatYAML
+}
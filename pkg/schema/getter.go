@@ -1,15 +1,25 @@
 package schema
 
 import (
+	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/invopop/jsonschema"
 )
 
-var (
-	denvclustrSchema     *jsonschema.Schema
-	denvclustrSchemaOnce sync.Once
-)
+// schemaVersion describes the root type of one version of the denvclustr file format.
+type schemaVersion struct {
+	root   func() any
+	once   sync.Once
+	schema *jsonschema.Schema
+}
+
+// schemaVersions lists the root type of every supported file format version.
+// When a breaking change is made, the previous root type is kept here under its version.
+var schemaVersions = map[string]*schemaVersion{
+	Version1: {root: func() any { return &DenvclustrRoot{} }},
+}
 
 func addCustomValidations(schema *jsonschema.Schema) {
 	properties := schema.Properties
@@ -21,20 +31,129 @@ func addCustomValidations(schema *jsonschema.Schema) {
 	}
 	if property, ok := properties.Get("devcontainers"); ok && property != nil {
 		property.UniqueItems = true
+		if forEach, ok := property.Items.Properties.Get("for_each"); ok && forEach != nil && forEach.Items != nil {
+			forEach.Items.Pattern = replicaNamePattern.String()
+		}
+		setPattern(property.Items, commitPattern.String(), "source", "commit")
+		if env, ok := property.Items.Properties.Get("env"); ok && env != nil {
+			env.PropertyNames = &jsonschema.Schema{Pattern: envNamePattern.String()}
+		}
+	}
+	if property, ok := properties.Get("defaults"); ok && property != nil {
+		setPattern(property, commitPattern.String(), "devcontainer", "source", "commit")
+		// Defaults are partial entries: any property may be omitted, including the required properties of nested objects
+		removeRequired(property)
+	}
+	for _, field := range requiredUnlessDefaulted {
+		schema.AllOf = append(schema.AllOf, requireUnlessDefaulted(field.entries, field.defaults, field.path, field.alternative))
+	}
+	if property, ok := properties.Get("variables"); ok && property != nil {
+		property.PropertyNames = &jsonschema.Schema{Pattern: referenceNamePattern.String()}
+		property.AdditionalProperties = &jsonschema.Schema{OneOf: []*jsonschema.Schema{{Type: "string"}, {Type: "null"}}}
+	}
+}
+
+// setPattern sets the pattern of a nested property, for patterns that cannot be written in a jsonschema tag.
+func setPattern(schema *jsonschema.Schema, pattern string, path ...string) {
+	for _, name := range path {
+		if schema == nil || schema.Properties == nil {
+			return
+		}
+		schema, _ = schema.Properties.Get(name)
+	}
+	if schema != nil {
+		schema.Pattern = pattern
+	}
+}
+
+// requiredUnlessDefaulted lists the properties of the node and devcontainer entries that are
+// required unless the defaults block sets them.
+// A property is not required either in the entries setting its alternative, if any.
+var requiredUnlessDefaulted = []struct {
+	entries     string
+	defaults    string
+	path        []string
+	alternative string
+}{
+	{"nodes", "node", []string{"infrastructure_id"}, ""},
+	{"nodes", "node", []string{"properties", "instance_type"}, ""},
+	{"nodes", "node", []string{"remote_access", "public_ssh_key"}, ""},
+	{"devcontainers", "devcontainer", []string{"node_id"}, ""},
+	{"devcontainers", "devcontainer", []string{"source", "url"}, "image"},
+}
+
+// requireUnlessDefaulted returns a schema requiring the property at path in every entry of an array,
+// unless the property is set in the given member of the defaults block or the entry sets the alternative property.
+func requireUnlessDefaulted(entries, defaults string, path []string, alternative string) *jsonschema.Schema {
+	items := requireProperty(path, &jsonschema.Schema{})
+	if alternative != "" {
+		items = &jsonschema.Schema{If: &jsonschema.Schema{Required: []string{alternative}}, Else: items}
+	}
+
+	// A missing array is reported by the root schema already
+	properties := jsonschema.NewProperties()
+	properties.Set(entries, &jsonschema.Schema{Items: items})
+	return &jsonschema.Schema{
+		If:   requireProperty([]string{"defaults", defaults}, requireProperty(path, &jsonschema.Schema{})),
+		Else: &jsonschema.Schema{Properties: properties},
+	}
+}
+
+// requireProperty returns a schema requiring the nested property at path, whose value matches the given schema.
+func requireProperty(path []string, value *jsonschema.Schema) *jsonschema.Schema {
+	for i := len(path) - 1; i >= 0; i-- {
+		properties := jsonschema.NewProperties()
+		properties.Set(path[i], value)
+		value = &jsonschema.Schema{Required: []string{path[i]}, Properties: properties}
+	}
+	return value
+}
+
+// removeRequired removes the required properties of a schema and of the schemas nested in it.
+func removeRequired(schema *jsonschema.Schema) {
+	schema.Required = nil
+	if schema.Properties != nil {
+		for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			removeRequired(pair.Value)
+		}
+	}
+	if schema.Items != nil {
+		removeRequired(schema.Items)
 	}
 }
 
 // GetSchema returns the JSON Schema for DenvclustrRoot.
 func GetSchema() *jsonschema.Schema {
-	denvclustrSchemaOnce.Do(func() {
+	schema, _ := GetSchemaForVersion(CurrentVersion)
+	return schema
+}
+
+// GetSchemaForVersion returns the JSON Schema of the given version of the denvclustr file format.
+func GetSchemaForVersion(version string) (*jsonschema.Schema, error) {
+	v, ok := schemaVersions[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported version %q (supported versions: %v)", version, SupportedVersions())
+	}
+
+	v.once.Do(func() {
 		reflector := &jsonschema.Reflector{
 			DoNotReference:             true,
 			RequiredFromJSONSchemaTags: true,
 			ExpandedStruct:             true,
 			AllowAdditionalProperties:  false,
 		}
-		denvclustrSchema = reflector.Reflect(&DenvclustrRoot{})
-		addCustomValidations(denvclustrSchema)
+		v.schema = reflector.Reflect(v.root())
+		addCustomValidations(v.schema)
 	})
-	return denvclustrSchema
+	return v.schema, nil
+}
+
+// SupportedVersions returns the supported versions of the denvclustr file format in ascending order.
+func SupportedVersions() []string {
+	versions := make([]string, 0, len(schemaVersions))
+	for version := range schemaVersions {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return compareVersions(versions[i], versions[j]) < 0 })
+	return versions
 }
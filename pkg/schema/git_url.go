@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Enum of git transports supported in repository URLs.
+type GitTransport string
+
+const (
+	GitTransportSSH   GitTransport = "ssh"
+	GitTransportHTTPS GitTransport = "https"
+	GitTransportGit   GitTransport = "git"
+	GitTransportFile  GitTransport = "file"
+)
+
+// GitURL is a parsed git remote URL.
+type GitURL struct {
+	Transport GitTransport
+	// User and Password are the credentials written in the URL, if any.
+	User     string
+	Password string
+	// Host and Port are empty for file URLs. Port is empty when the default port of the transport is used.
+	Host string
+	Port string
+	// Path is the absolute path of the repository on the host, e.g. "/org/repo.git".
+	Path string
+}
+
+// scpLikePattern matches the scp-like syntax of SSH URLs, e.g. "git@github.com:org/repo.git".
+var scpLikePattern = regexp.MustCompile(`^(?:([^@/:]+)@)?([^@/:]+):(.+)$`)
+
+// commitPattern matches a full or abbreviated SHA-1 commit id, or a full SHA-256 commit id.
+var commitPattern = regexp.MustCompile(`^([0-9a-f]{7,40}|[0-9a-f]{64})$`)
+
+// hostLabelPattern matches a label of a host name.
+var hostLabelPattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+
+// ParseGitURL parses a git remote URL in one of the forms understood by git clone:
+// "ssh://[user@]host[:port]/path", the scp-like "[user@]host:path", "https://host[:port]/path",
+// "git://host[:port]/path" and "file:///path". Plain HTTP and local paths are rejected.
+func ParseGitURL(raw string) (*GitURL, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("URL is empty")
+	}
+	if strings.ContainsAny(raw, " \t\r\n") {
+		return nil, fmt.Errorf("URL must not contain whitespace")
+	}
+
+	if !strings.Contains(raw, "://") {
+		match := scpLikePattern.FindStringSubmatch(raw)
+		if match == nil {
+			return nil, fmt.Errorf("%q is neither a URL nor an scp-like SSH address such as git@github.com:org/repo.git", raw)
+		}
+		u := &GitURL{Transport: GitTransportSSH, User: match[1], Host: match[2], Path: "/" + strings.TrimPrefix(match[3], "/")}
+		return u, u.check()
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid URL", raw)
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return nil, fmt.Errorf("URL must not have a query or a fragment")
+	}
+
+	u := &GitURL{Host: parsed.Hostname(), Port: parsed.Port(), Path: parsed.Path}
+	switch scheme := strings.ToLower(parsed.Scheme); scheme {
+	case "ssh", "git+ssh", "ssh+git":
+		u.Transport = GitTransportSSH
+	case "https":
+		u.Transport = GitTransportHTTPS
+	case "git":
+		u.Transport = GitTransportGit
+	case "file":
+		u.Transport = GitTransportFile
+	case "http":
+		return nil, fmt.Errorf("plain HTTP is not supported, use https://")
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q, expected ssh, https, git or file", scheme)
+	}
+	if parsed.User != nil {
+		u.User = parsed.User.Username()
+		u.Password, _ = parsed.User.Password()
+	}
+	return u, u.check()
+}
+
+// check validates the host, port and path of a parsed URL.
+func (u *GitURL) check() error {
+	if u.Transport == GitTransportFile {
+		if u.Host != "" && u.Host != "localhost" {
+			return fmt.Errorf("file URLs cannot name a host, use file:///path")
+		}
+		u.Host, u.Port = "", ""
+	} else if err := checkHost(u.Host); err != nil {
+		return err
+	}
+
+	if u.Port != "" {
+		if port, err := strconv.Atoi(u.Port); err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("port %q is out of range (1-65535)", u.Port)
+		}
+	}
+
+	if strings.Trim(u.Path, "/") == "" {
+		return fmt.Errorf("URL has no repository path")
+	}
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment == ".." {
+			return fmt.Errorf("repository path must not contain '..'")
+		}
+	}
+	return nil
+}
+
+// checkHost validates a host name or an IP address.
+func checkHost(host string) error {
+	if host == "" {
+		return fmt.Errorf("URL has no host")
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if len(host) > 253 {
+		return fmt.Errorf("host %q is too long", host)
+	}
+	for _, label := range strings.Split(host, ".") {
+		if len(label) > 63 || !hostLabelPattern.MatchString(label) {
+			return fmt.Errorf("invalid host %q", host)
+		}
+	}
+	return nil
+}
+
+// IsSSH reports whether the repository is cloned over SSH, which requires an SSH key.
+func (u *GitURL) IsSSH() bool {
+	return u.Transport == GitTransportSSH
+}
+
+// String returns the normalized form of the URL: scp-like addresses are written as ssh:// URLs,
+// the host is lowercased and the password is left out.
+func (u *GitURL) String() string {
+	if u.Transport == GitTransportFile {
+		return "file://" + u.Path
+	}
+
+	var sb strings.Builder
+	sb.WriteString(string(u.Transport) + "://")
+	if u.User != "" {
+		sb.WriteString(url.PathEscape(u.User) + "@")
+	}
+	host := strings.ToLower(u.Host)
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	sb.WriteString(host)
+	if u.Port != "" {
+		sb.WriteString(":" + u.Port)
+	}
+	sb.WriteString(u.Path)
+	return sb.String()
+}
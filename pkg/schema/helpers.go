@@ -8,3 +8,19 @@ func collectNodeMap(root *DenvclustrRoot) map[string]*Node {
 	}
 	return result
 }
+
+// openVSCodeServerPort returns the port of an OpenVSCode Server, nil when it has none.
+func openVSCodeServerPort(server *DevcontainerOpenVSCodeServer) *int {
+	if server == nil {
+		return nil
+	}
+	return server.Port
+}
+
+// sshPort returns the port of the SSH access of a devcontainer, nil when it has none.
+func sshPort(ssh *DevcontainerSSH) *int {
+	if ssh == nil {
+		return nil
+	}
+	return ssh.Port
+}
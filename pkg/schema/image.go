@@ -0,0 +1,37 @@
+package schema
+
+import "regexp"
+
+// imageReferencePattern matches a container image reference, "[registry[:port]/]repository[:tag][@digest]",
+// e.g. "ghcr.io/example/api-dev:1.4.2" or "ubuntu@sha256:<64 hex digits>".
+var imageReferencePattern = regexp.MustCompile(
+	`^(?:(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?)/)?` +
+		`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+		`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?(?:@sha256:[a-f0-9]{64})?$`,
+)
+
+// DevcontainerImage is a prebuilt container image the devcontainer runs, instead of building it from a repository.
+type DevcontainerImage struct {
+	Reference   TrimmedString                 `json:"reference" jsonschema:"required,minLength=1" jsonschema_description:"Reference of the image in its registry, e.g. 'ghcr.io/example/api-dev:1.4.2' or an image pinned by digest ('...@sha256:...'). Images without a registry are pulled from Docker Hub."`
+	Credentials *DevcontainerImageCredentials `json:"credentials,omitempty" jsonschema_description:"Credentials used to pull the image from a private registry. If omitted, the image is pulled anonymously."`
+}
+
+// DevcontainerImageCredentials locates the registry credentials of an image in a secret backend.
+type DevcontainerImageCredentials struct {
+	Reference TrimmedString `json:"reference" jsonschema:"required,minLength=1" jsonschema_description:"Reference identifier of the secret holding the registry credentials, as a JSON object with 'username' and 'password' members."`
+	Source    SshKeySource  `json:"source" jsonschema:"required,enum=secrets_manager,enum=ssm_parameter_store" jsonschema_description:"Secret backend service where the registry credentials are stored. Must be either 'secrets_manager' or 'ssm_parameter_store'."`
+}
+
+// validateImage checks the image of the devcontainer at index i.
+func validateImage(devcontainer *Devcontainer, i int, c *collector) {
+	reference := string(devcontainer.Image.Reference)
+	if reference == "" {
+		c.add(RuleMissingField, pointer("devcontainers", i, "image", "reference"), "%s: image.reference is missing", c.devcontainer(i, devcontainer))
+		return
+	}
+	if !imageReferencePattern.MatchString(reference) {
+		c.add(RuleInvalidImage, pointer("devcontainers", i, "image", "reference"),
+			"%s: invalid image reference %q, expected [registry[:port]/]repository[:tag][@sha256:digest]", c.devcontainer(i, devcontainer), reference,
+		)
+	}
+}
@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImage(t *testing.T) {
+	const base = `name: image-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+defaults:
+  devcontainer:
+    source: {url: "https://github.com/example/web.git"}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+devcontainers:
+  - {id: web, node_id: node1}
+  - {id: api, node_id: node1, %s}
+`
+
+	t.Run("an image replaces the default source", func(t *testing.T) {
+		root, err := Parse([]byte(fmt.Sprintf(base,
+			`image: {reference: "123456789012.dkr.ecr.eu-central-1.amazonaws.com/api-dev:1.4.2", credentials: {source: secrets_manager, reference: registry-credentials}}`,
+		)), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		assert.Equal(t, TrimmedString("https://github.com/example/web.git"), root.Devcontainers[0].Source.URL)
+		assert.Nil(t, root.Devcontainers[1].Source)
+		assert.Equal(t, &DevcontainerImage{
+			Reference:   "123456789012.dkr.ecr.eu-central-1.amazonaws.com/api-dev:1.4.2",
+			Credentials: &DevcontainerImageCredentials{Reference: "registry-credentials", Source: SshKeySourceSecretsManager},
+		}, root.Devcontainers[1].Image)
+	})
+
+	t.Run("an image needs no source without defaults", func(t *testing.T) {
+		data := []byte(`name: image-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+devcontainers:
+  - {id: api, node_id: node1, image: {reference: "ghcr.io/example/api-dev@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}}
+  - {id: web, node_id: node1}
+`)
+		errs, err := Validate(data, WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		require.Len(t, errs, 1)
+		assert.Equal(t, RuleSchemaPrefix+"required", errs[0].Code)
+		assert.Equal(t, "/devcontainers/1", errs[0].Pointer)
+	})
+
+	tests := []struct {
+		name    string
+		image   string
+		code    string
+		pointer string
+	}{
+		{"image and source", `image: {reference: "ubuntu:24.04"}, source: {url: "https://github.com/example/api.git"}`, RuleConflictingFields, "/devcontainers/1/image"},
+		{"invalid reference", `image: {reference: "GHCR.io/Example/API:latest"}`, RuleInvalidImage, "/devcontainers/1/image/reference"},
+		{"credentials without backend", `image: {reference: "ubuntu:24.04", credentials: {reference: registry-credentials}}`, RuleSchemaPrefix + "required", "/devcontainers/1/image/credentials"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate([]byte(fmt.Sprintf(base, tt.image)), WithFormat(FormatYAML))
+			require.NoError(t, err)
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.code, errs[0].Code)
+			assert.Equal(t, tt.pointer, errs[0].Pointer)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package schema
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeKey is the name of the top-level property listing the fragments to include.
+const includeKey = "include"
+
+// fragmentKeys lists the top-level properties a fragment may define.
+// The arrays of a fragment are appended to the arrays of the including file.
+var fragmentKeys = []string{"infrastructure", "nodes", "devcontainers"}
+
+// includer loads the fragments listed by the include property of a document and its fragments.
+type includer struct {
+	// loaded records the absolute path of the files already included, so each file is included once.
+	loaded map[string]bool
+	c      *collector
+}
+
+// expandIncludes appends the arrays of the included fragments to the arrays of the document,
+// in the order of the include list, and removes the include property. Glob patterns are expanded
+// in lexical order. Paths are relative to the directory of the including file.
+// The returned errors are already located, since they may belong to a fragment.
+func expandIncludes(doc *document, options *parseOptions) (ValidationErrors, error) {
+	if doc.root.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	inc := &includer{loaded: make(map[string]bool), c: &collector{}}
+	if options.path != "" {
+		absolute, err := filepath.Abs(options.path)
+		if err != nil {
+			return nil, err
+		}
+		inc.loaded[absolute] = true
+	}
+	if err := inc.include(doc, doc, options.baseDir); err != nil {
+		return nil, err
+	}
+	return inc.c.errs, nil
+}
+
+// include loads the fragments listed by source, which is either the document itself or one of its fragments.
+func (inc *includer) include(doc, source *document, dir string) error {
+	_, list := childNode(source.root, includeKey)
+	if list == nil {
+		return nil
+	}
+	defer removeMember(source.root, includeKey)
+
+	c := &collector{}
+	defer func() {
+		source.locateErrors(c.errs)
+		inc.c.errs = append(inc.c.errs, c.errs...)
+	}()
+
+	if list.Kind != yaml.SequenceNode {
+		c.add(RuleInvalidInclude, pointer(includeKey), "include must be a list of paths")
+		return nil
+	}
+
+	for i, item := range list.Content {
+		item = resolveAlias(item)
+		if item.Kind != yaml.ScalarNode || item.Value == "" {
+			c.add(RuleInvalidInclude, pointer(includeKey, i), "include entries must be non-empty paths")
+			continue
+		}
+
+		pattern := item.Value
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			c.add(RuleInvalidInclude, pointer(includeKey, i), "invalid include pattern %q: %v", item.Value, err)
+			continue
+		}
+		if len(matches) == 0 {
+			if hasMeta(item.Value) {
+				c.warn(RuleInvalidInclude, pointer(includeKey, i), "include pattern %q matches no file", item.Value)
+			} else {
+				c.add(RuleInvalidInclude, pointer(includeKey, i), "included file %q not found", item.Value)
+			}
+			continue
+		}
+
+		for _, match := range matches {
+			if err := inc.load(doc, match, pointer(includeKey, i), c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// load reads a fragment and appends its arrays to the document.
+func (inc *includer) load(doc *document, path, p string, c *collector) error {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if inc.loaded[absolute] {
+		return nil
+	}
+	inc.loaded[absolute] = true
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.add(RuleInvalidInclude, p, "read included file: %v", err)
+		return nil
+	}
+
+	fragment, err := decodeDocument(data, DetectFormat(path, data))
+	if err != nil {
+		var syntaxErr *ValidationError
+		if errors.As(err, &syntaxErr) {
+			syntaxErr.File = path
+			inc.c.errs = append(inc.c.errs, syntaxErr)
+			return nil
+		}
+		return err
+	}
+	fragment.markOrigin(fragment.root, path)
+	doc.importOrigins(fragment)
+
+	if fragment.root.Kind != yaml.MappingNode {
+		errs := ValidationErrors{{Code: RuleInvalidInclude, Severity: SeverityError, Message: "included file must contain an object"}}
+		fragment.locateErrors(errs)
+		inc.c.errs = append(inc.c.errs, errs...)
+		return nil
+	}
+
+	if errs, err := upgradeDocument(fragment); errs != nil || err != nil {
+		inc.c.errs = append(inc.c.errs, errs...)
+		return err
+	}
+
+	fc := &collector{}
+	for i := 0; i+1 < len(fragment.root.Content); i += 2 {
+		key, value := fragment.root.Content[i], resolveAlias(fragment.root.Content[i+1])
+		switch {
+		case key.Value == includeKey || key.Value == versionKey:
+		case !isFragmentKey(key.Value):
+			fc.add(RuleInvalidInclude, pointer(key.Value), "included files may only define %v, found %q", fragmentKeys, key.Value)
+		case value.Kind != yaml.SequenceNode:
+			fc.add(RuleInvalidInclude, pointer(key.Value), "%s must be a list", key.Value)
+		default:
+			appendEntries(doc.root, key, value)
+		}
+	}
+	fragment.locateErrors(fc.errs)
+	inc.c.errs = append(inc.c.errs, fc.errs...)
+
+	// Nested fragments are appended after the arrays of the fragment including them
+	return inc.include(doc, fragment, filepath.Dir(path))
+}
+
+// appendEntries appends the entries of a fragment array to the array of the same name in root,
+// adding the array if root does not define it yet.
+func appendEntries(root, key, entries *yaml.Node) {
+	if _, target := childNode(root, key.Value); target != nil {
+		if target.Kind == yaml.SequenceNode {
+			target.Content = append(target.Content, entries.Content...)
+		}
+		return
+	}
+
+	sequence := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: entries.Line, Column: entries.Column}
+	sequence.Content = append(sequence.Content, entries.Content...)
+	root.Content = append(root.Content, key, sequence)
+}
+
+func isFragmentKey(key string) bool {
+	for _, fragmentKey := range fragmentKeys {
+		if key == fragmentKey {
+			return true
+		}
+	}
+	return false
+}
+
+// removeMember removes the member with the given key from a mapping node.
+func removeMember(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// hasMeta reports whether a path contains any of the special characters of filepath.Match.
+func hasMeta(path string) bool {
+	for _, c := range path {
+		switch c {
+		case '*', '?', '[', '\\':
+			return true
+		}
+	}
+	return false
+}
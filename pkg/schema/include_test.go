@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWithIncludes(t *testing.T) {
+	t.Run("fragments are appended in order", func(t *testing.T) {
+		data, err := testDataFS.ReadFile("testdata/include_main.yaml")
+		require.NoError(t, err, "Failed to read test file")
+
+		root, err := Parse(data, WithFormat(FormatYAML), WithBaseDir("testdata"))
+		require.NoError(t, err)
+
+		assert.Empty(t, root.Include)
+		require.Len(t, root.Infrastructure, 1)
+		require.Len(t, root.Nodes, 1)
+		var ids []TrimmedString
+		for _, devcontainer := range root.Devcontainers {
+			ids = append(ids, devcontainer.Id)
+		}
+		assert.Equal(t, []TrimmedString{"tools", "api", "web", "docs"}, ids)
+	})
+
+	t.Run("duplicate ids name their fragment", func(t *testing.T) {
+		data, err := testDataFS.ReadFile("testdata/include_duplicate.yaml")
+		require.NoError(t, err, "Failed to read test file")
+
+		_, err = Parse(data, WithFormat(FormatYAML), WithBaseDir("testdata"))
+
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 1)
+		assert.Equal(t, RuleDuplicateId, errs[0].Code)
+		assert.Equal(t, "/devcontainers/2/id", errs[0].Pointer)
+		assert.Equal(t, "testdata/include/nested/docs.yaml", errs[0].File)
+		assert.Equal(t, 2, errs[0].Line)
+		assert.Equal(t, `devcontainer id "docs" is duplicated (defined in testdata/include/nested/docs.yaml, first defined in the main file)`, errs[0].Message)
+	})
+
+	t.Run("invalid includes", func(t *testing.T) {
+		data, err := testDataFS.ReadFile("testdata/include_invalid.yaml")
+		require.NoError(t, err, "Failed to read test file")
+
+		errs, err := Validate(data, WithFormat(FormatYAML), WithBaseDir("testdata"))
+		require.NoError(t, err)
+
+		expected := ValidationErrors{
+			{Code: RuleInvalidInclude, Pointer: "/include/0", Line: 3, Column: 5, Severity: SeverityError},
+			{Code: RuleInvalidInclude, Pointer: "/include/1", Line: 4, Column: 5, Severity: SeverityWarning},
+			{Code: RuleInvalidInclude, Pointer: "/name", File: "testdata/include_main.yaml", Line: 1, Column: 1, Severity: SeverityError},
+		}
+		require.Len(t, errs, len(expected))
+		for i := range errs {
+			assert.NotEmpty(t, errs[i].Message)
+			errs[i].Message = ""
+			assert.Equal(t, expected[i], errs[i])
+		}
+	})
+
+	t.Run("the parsed file is not included again", func(t *testing.T) {
+		dir := t.TempDir()
+		main := filepath.Join(dir, "denvclustr.yaml")
+		data := []byte(`name: cluster
+include: ["*.yaml"]
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.micro}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+`)
+		require.NoError(t, os.WriteFile(main, data, 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "web.yaml"), []byte(`devcontainers:
+  - {id: web, node_id: node1, source: {url: "https://github.com/example/web.git"}}
+`), 0o644))
+
+		root, err := Parse(data, WithFormat(FormatYAML), WithPath(main))
+		require.NoError(t, err)
+		assert.Len(t, root.Infrastructure, 1)
+		assert.Len(t, root.Nodes, 1)
+		assert.Len(t, root.Devcontainers, 1)
+	})
+}
@@ -16,8 +16,9 @@ const (
 
 // Infrastructure describes a single infrastructure backend.
 type Infrastructure struct {
-	Id       TrimmedString      `json:"id" jsonschema:"required,minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Unique identifier for this infrastructure provider within the cluster. Must start with a letter or underscore, can contain alphanumeric characters, underscores, and hyphens. Cannot end with a hyphen."`
-	Kind     InfrastructureKind `json:"kind" jsonschema:"required,enum=vm" jsonschema_description:"Type of infrastructure. Currently only 'vm' is supported."`
-	Provider Provider           `json:"provider" jsonschema:"required,enum=aws" jsonschema_description:"Name of the platform. Currently only 'aws' is supported."`
-	Region   TrimmedString      `json:"region" jsonschema:"required,minLength=1" jsonschema_description:"Geographic location where resources will be deployed (e.g., 'us-west-2' for AWS). Must be a valid region identifier for the specified provider."`
+	Id       TrimmedString            `json:"id" jsonschema:"required,minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Unique identifier for this infrastructure provider within the cluster. Must start with a letter or underscore, can contain alphanumeric characters, underscores, and hyphens. Cannot end with a hyphen."`
+	Kind     InfrastructureKind       `json:"kind" jsonschema:"required,enum=vm" jsonschema_description:"Type of infrastructure. Currently only 'vm' is supported."`
+	Provider Provider                 `json:"provider" jsonschema:"required,enum=aws" jsonschema_description:"Name of the platform. Currently only 'aws' is supported."`
+	Region   TrimmedString            `json:"region" jsonschema:"required,minLength=1" jsonschema_description:"Geographic location where resources will be deployed (e.g., 'us-west-2' for AWS). Must be a region of the provider catalog."`
+	Tags     map[string]TrimmedString `json:"tags,omitempty" jsonschema_description:"Tags of every resource deployed on this infrastructure, e.g. for cost allocation. Merged with the tags of the levels above, whose values are overridden. Keys are at most 128 characters long and cannot start with 'aws:', values at most 256 characters."`
 }
@@ -0,0 +1,76 @@
+package schema
+
+// standardizeJSONC converts JSON with comments and trailing commas into standard JSON.
+// Comments and trailing commas are replaced with spaces rather than removed,
+// so byte offsets, lines and columns still match the original input.
+func standardizeJSONC(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	// Blank out comments
+	for i := 0; i < len(out); i++ {
+		switch {
+		case out[i] == '"':
+			i = skipJSONString(out, i)
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			start := i
+			for ; i < len(out) && !(out[i] == '*' && i+1 < len(out) && out[i+1] == '/'); i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			if i >= len(out) {
+				return nil, &jsoncSyntaxError{msg: "unterminated block comment", offset: start}
+			}
+			out[i], out[i+1] = ' ', ' '
+			i++
+		}
+	}
+
+	// Blank out commas directly followed by a closing bracket
+	for i := 0; i < len(out); i++ {
+		switch out[i] {
+		case '"':
+			i = skipJSONString(out, i)
+		case ',':
+			j := i + 1
+			for j < len(out) && isJSONWhitespace(out[j]) {
+				j++
+			}
+			if j < len(out) && (out[j] == '}' || out[j] == ']') {
+				out[i] = ' '
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// skipJSONString returns the index of the closing quote of the string starting at start.
+func skipJSONString(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(data)
+}
+
+func isJSONWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// jsoncSyntaxError describes a JSONC syntax error at a byte offset of the input.
+type jsoncSyntaxError struct {
+	msg    string
+	offset int
+}
+
+func (e *jsoncSyntaxError) Error() string { return e.msg }
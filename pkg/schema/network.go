@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+type NodeAllowedCIDRs struct {
+	SSH              []TrimmedString `json:"ssh,omitzero" jsonschema_description:"CIDR blocks allowed to connect over SSH, to the node and to its devcontainers, e.g. '10.0.0.0/8'. An empty list allows no one. If not specified, the default of the module is used."`
+	OpenVsCodeServer []TrimmedString `json:"openvscode_server,omitzero" jsonschema_description:"CIDR blocks allowed to connect to the OpenVSCode Server of the devcontainers. An empty list allows no one. If not specified, the default of the module is used."`
+}
+
+type NodeNetwork struct {
+	VpcId             TrimmedString     `json:"vpc_id,omitempty" jsonschema:"pattern=^vpc-([0-9a-f]{8}|[0-9a-f]{17})$" jsonschema_description:"Id of the VPC the node is deployed into. If not specified, the default VPC of the region is used."`
+	SubnetId          TrimmedString     `json:"subnet_id,omitempty" jsonschema:"pattern=^subnet-([0-9a-f]{8}|[0-9a-f]{17})$" jsonschema_description:"Id of the subnet the node is deployed into. Must belong to 'vpc_id' when both are set."`
+	AssociatePublicIP *bool             `json:"associate_public_ip,omitempty" jsonschema_description:"Whether the node gets a public IP address. Nodes without one are only reachable from their network, and cannot use DNS."`
+	AllowedCIDRs      *NodeAllowedCIDRs `json:"allowed_cidrs,omitempty" jsonschema_description:"CIDR blocks allowed to connect to the node, per protocol."`
+}
+
+// validateNetworks checks the CIDR blocks allowed to connect to the nodes, and that nodes using DNS get a public IP address.
+func validateNetworks(root *DenvclustrRoot, c *collector) {
+	for i, node := range root.Nodes {
+		network := node.Network
+		if network == nil {
+			continue
+		}
+		p := pointer("nodes", i, "network")
+
+		if network.AssociatePublicIP != nil && !*network.AssociatePublicIP && node.DNS != nil {
+			c.add(RuleConflictingFields, p+pointer("associate_public_ip"),
+				"node %q: dns requires a public IP address, remove dns or set associate_public_ip to true", node.Id,
+			)
+		}
+
+		if network.AllowedCIDRs == nil {
+			continue
+		}
+		for _, protocol := range []struct {
+			name  string
+			cidrs []TrimmedString
+		}{
+			{"ssh", network.AllowedCIDRs.SSH},
+			{"openvscode_server", network.AllowedCIDRs.OpenVsCodeServer},
+		} {
+			for j, cidr := range protocol.cidrs {
+				prefix, err := parseCIDR(string(cidr))
+				if err != nil {
+					c.add(RuleInvalidCIDR, p+pointer("allowed_cidrs", protocol.name, j), "node %q: allowed_cidrs.%s[%d]: %v", node.Id, protocol.name, j, err)
+				} else if prefix.Bits() == 0 {
+					c.warn(RuleInvalidCIDR, p+pointer("allowed_cidrs", protocol.name, j),
+						"node %q: allowed_cidrs.%s[%d]: %s allows connections from anywhere", node.Id, protocol.name, j, cidr,
+					)
+				}
+			}
+		}
+	}
+}
+
+// parseCIDR parses an IPv4 or IPv6 CIDR block. Blocks with host bits set, e.g. "10.0.0.1/8", are rejected
+// with the block they probably mean, as AWS rejects them too.
+func parseCIDR(cidr string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%q is not a CIDR block, e.g. 10.0.0.0/8", cidr)
+	}
+	if masked := prefix.Masked(); masked != prefix {
+		return netip.Prefix{}, fmt.Errorf("%q has host bits set, did you mean %s?", cidr, masked)
+	}
+	return prefix, nil
+}
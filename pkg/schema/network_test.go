@@ -0,0 +1,114 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateNetwork(t *testing.T) {
+	const base = `name: corporate-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - id: node1
+    infrastructure_id: aws
+    properties: {instance_type: t3.large}
+    remote_access: {public_ssh_key: ~/.ssh/id.pub}
+    network: %s
+devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}}
+`
+
+	t.Run("valid network", func(t *testing.T) {
+		root, err := Parse([]byte(fmt.Sprintf(base,
+			`{vpc_id: vpc-0a1b2c3d4e5f60718, subnet_id: subnet-0a1b2c3d, associate_public_ip: false, allowed_cidrs: {ssh: [10.0.0.0/8, "fd00::/8"], openvscode_server: []}}`,
+		)), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		network := root.Nodes[0].Network
+		require.NotNil(t, network)
+		assert.Equal(t, TrimmedString("vpc-0a1b2c3d4e5f60718"), network.VpcId)
+		assert.False(t, *network.AssociatePublicIP)
+		assert.Equal(t, []TrimmedString{"10.0.0.0/8", "fd00::/8"}, network.AllowedCIDRs.SSH)
+		assert.NotNil(t, network.AllowedCIDRs.OpenVsCodeServer)
+		assert.Empty(t, network.AllowedCIDRs.OpenVsCodeServer)
+	})
+
+	t.Run("network from defaults", func(t *testing.T) {
+		root, err := Parse([]byte(`name: corporate-cluster
+defaults:
+  node:
+    network: {vpc_id: vpc-0a1b2c3d, allowed_cidrs: {ssh: [10.0.0.0/8]}}
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}, network: {subnet_id: subnet-0a1b2c3d}}
+devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}}
+`), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		assert.Equal(t, &NodeNetwork{
+			VpcId:        "vpc-0a1b2c3d",
+			SubnetId:     "subnet-0a1b2c3d",
+			AllowedCIDRs: &NodeAllowedCIDRs{SSH: []TrimmedString{"10.0.0.0/8"}},
+		}, root.Nodes[0].Network)
+	})
+
+	t.Run("open to anyone", func(t *testing.T) {
+		errs, err := Validate([]byte(fmt.Sprintf(base, `{allowed_cidrs: {openvscode_server: [0.0.0.0/0]}}`)), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		require.Len(t, errs, 1)
+		assert.Equal(t, SeverityWarning, errs[0].Severity)
+		assert.Equal(t, "/nodes/0/network/allowed_cidrs/openvscode_server/0", errs[0].Pointer)
+		assert.Equal(t, `node "node1": allowed_cidrs.openvscode_server[0]: 0.0.0.0/0 allows connections from anywhere`, errs[0].Message)
+	})
+
+	tests := []struct {
+		name    string
+		network string
+		code    string
+		pointer string
+		message string
+	}{
+		{"invalid CIDR", `{allowed_cidrs: {ssh: [10.0.0/8]}}`, RuleInvalidCIDR,
+			"/nodes/0/network/allowed_cidrs/ssh/0", `node "node1": allowed_cidrs.ssh[0]: "10.0.0/8" is not a CIDR block, e.g. 10.0.0.0/8`},
+		{"address without prefix length", `{allowed_cidrs: {ssh: [10.1.2.3]}}`, RuleInvalidCIDR,
+			"/nodes/0/network/allowed_cidrs/ssh/0", `node "node1": allowed_cidrs.ssh[0]: "10.1.2.3" is not a CIDR block, e.g. 10.0.0.0/8`},
+		{"host bits set", `{allowed_cidrs: {ssh: [10.0.0.0/8, 192.168.1.10/24]}}`, RuleInvalidCIDR,
+			"/nodes/0/network/allowed_cidrs/ssh/1", `node "node1": allowed_cidrs.ssh[1]: "192.168.1.10/24" has host bits set, did you mean 192.168.1.0/24?`},
+		{"invalid VPC id", `{vpc_id: my-vpc}`, RuleSchemaPrefix + "pattern",
+			"/nodes/0/network/vpc_id", "'my-vpc' does not match pattern '^vpc-([0-9a-f]{8}|[0-9a-f]{17})$'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate([]byte(fmt.Sprintf(base, tt.network)), WithFormat(FormatYAML))
+			require.NoError(t, err)
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.code, errs[0].Code)
+			assert.Equal(t, tt.pointer, errs[0].Pointer)
+			assert.Equal(t, tt.message, errs[0].Message)
+		})
+	}
+
+	t.Run("DNS without public IP", func(t *testing.T) {
+		errs, err := Validate([]byte(`name: corporate-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}, dns: {high_level_domain: dev.example.com}, network: {associate_public_ip: false}}
+devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}}
+`), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		require.Len(t, errs, 1)
+		assert.Equal(t, RuleConflictingFields, errs[0].Code)
+		assert.Equal(t, "/nodes/0/network/associate_public_ip", errs[0].Pointer)
+	})
+}
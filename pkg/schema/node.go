@@ -1,11 +1,89 @@
 package schema
 
+// Enum of supported CPU architectures of nodes.
+type Architecture string
+
+const (
+	ArchitectureX86_64 Architecture = "x86_64"
+	ArchitectureArm64  Architecture = "arm64"
+)
+
+// Enum of the purchasing options of nodes.
+type Market string
+
+const (
+	MarketOnDemand Market = "on_demand"
+	MarketSpot     Market = "spot"
+)
+
+// Enum of what happens to a spot node when it is interrupted.
+type InterruptionBehavior string
+
+const (
+	InterruptionBehaviorStop      InterruptionBehavior = "stop"
+	InterruptionBehaviorTerminate InterruptionBehavior = "terminate"
+)
+
+type NodeRootVolume struct {
+	Size      *int          `json:"size,omitempty" jsonschema:"minimum=8,maximum=16384" jsonschema_description:"Size of the root volume in GiB. If not specified, the size of the AMI is used."`
+	Encrypted *bool         `json:"encrypted,omitempty" jsonschema_description:"Whether the root volume is encrypted. If not specified, the EBS encryption by default setting of the account is used."`
+	KmsKeyId  TrimmedString `json:"kms_key_id,omitempty" jsonschema:"minLength=1" jsonschema_description:"Id, alias or ARN of the KMS key encrypting the root volume. If not specified, the AWS managed key is used. Requires 'encrypted' to be true."`
+}
+
 type NodeProperties struct {
-	InstanceType TrimmedString `json:"instance_type" jsonschema:"required,minLength=1" jsonschema_description:"The machine type or class used to provision this node, specific to the target infrastructure."`
+	InstanceType         TrimmedString        `json:"instance_type,omitempty" jsonschema:"minLength=1" jsonschema_description:"The machine type or class used to provision this node, specific to the target infrastructure and listed in its provider catalog. Required unless set in 'defaults.node'."`
+	Architecture         Architecture         `json:"architecture,omitempty" jsonschema:"enum=x86_64,enum=arm64" jsonschema_description:"CPU architecture of the node and its AMI: 'x86_64' or 'arm64', e.g. for Graviton instances. Must match the architecture of the instance type. If not specified, the architecture of the instance type is used."`
+	AMI                  TrimmedString        `json:"ami,omitempty" jsonschema:"pattern=^ami-([0-9a-f]{8}|[0-9a-f]{17})$" jsonschema_description:"Id of the AMI the node boots from, e.g. 'ami-0123456789abcdef0'. Its architecture must be set in 'architecture'. If not specified, the module picks an AMI for the architecture."`
+	RootVolume           *NodeRootVolume      `json:"root_volume,omitempty" jsonschema_description:"Size and encryption of the root EBS volume of the node."`
+	RequireIMDSv2        *bool                `json:"require_imdsv2,omitempty" jsonschema_description:"Whether the instance metadata service only accepts IMDSv2 session tokens. If not specified, the default of the module is used."`
+	Market               Market               `json:"market,omitempty" jsonschema:"enum=on_demand,enum=spot" jsonschema_description:"Purchasing option of the node: 'on_demand' or 'spot', which is cheaper but can be interrupted. Spot nodes cannot use DNS. If not specified, 'on_demand' is used."`
+	MaxPrice             *float64             `json:"max_price,omitempty" jsonschema:"exclusiveMinimum=0" jsonschema_description:"Maximum hourly price in USD paid for a spot node. If not specified, the on-demand price is the maximum. Requires 'market' to be 'spot'."`
+	InterruptionBehavior InterruptionBehavior `json:"interruption_behavior,omitempty" jsonschema:"enum=stop,enum=terminate" jsonschema_description:"What happens to a spot node when it is interrupted: 'stop' keeps its volume, so its devcontainers resume when capacity is back, while 'terminate' would delete it with the workspaces and is rejected. If not specified, 'stop' is used. Requires 'market' to be 'spot'."`
+}
+
+// MarketType returns the purchasing option of the node, which is on-demand unless set.
+func (p NodeProperties) MarketType() Market {
+	if p.Market == "" {
+		return MarketOnDemand
+	}
+	return p.Market
+}
+
+// SpotInterruptionBehavior returns what happens to a spot node when it is interrupted, which is stop unless set.
+func (p NodeProperties) SpotInterruptionBehavior() InterruptionBehavior {
+	if p.InterruptionBehavior == "" {
+		return InterruptionBehaviorStop
+	}
+	return p.InterruptionBehavior
+}
+
+// Enum of the ways nodes and their devcontainers are reached.
+type AccessMode string
+
+const (
+	AccessModePublic     AccessMode = "public"
+	AccessModeBastion    AccessMode = "bastion"
+	AccessModeSSMSession AccessMode = "ssm_session"
+)
+
+type NodeBastion struct {
+	Host TrimmedString `json:"host" jsonschema:"required,minLength=1" jsonschema_description:"Host name or IP address of the jump host SSH connections go through."`
+	User TrimmedString `json:"user,omitempty" jsonschema:"minLength=1" jsonschema_description:"User on the jump host. If not specified, the local SSH configuration decides."`
+	Port *int          `json:"port,omitempty" jsonschema:"minimum=1,maximum=65535" jsonschema_description:"SSH port of the jump host. If not specified, port 22 is used."`
 }
 
 type NodeRemoteAccess struct {
-	PublicSSHKey TrimmedString `json:"public_ssh_key" jsonschema:"required,minLength=1" jsonschema_description:"Path to local public SSH key."`
+	PublicSSHKey TrimmedString `json:"public_ssh_key,omitempty" jsonschema:"minLength=1" jsonschema_description:"Path to local public SSH key. Required unless set in 'defaults.node'."`
+	Mode         AccessMode    `json:"mode,omitempty" jsonschema:"enum=public,enum=bastion,enum=ssm_session" jsonschema_description:"How the node and its devcontainers are reached: 'public' connects to the public IP address of the node, 'bastion' goes through the jump host set in 'bastion', and 'ssm_session' tunnels SSH through AWS Systems Manager sessions, so the node needs no public IP address nor open port. If not specified, 'public' is used."`
+	Bastion      *NodeBastion  `json:"bastion,omitempty" jsonschema_description:"Jump host SSH connections go through. Required when 'mode' is 'bastion', and only allowed then."`
+}
+
+// AccessMode returns the access mode of the node, which is public unless set.
+func (r NodeRemoteAccess) AccessMode() AccessMode {
+	if r.Mode == "" {
+		return AccessModePublic
+	}
+	return r.Mode
 }
 
 type NodeDNS struct {
@@ -13,9 +91,12 @@ type NodeDNS struct {
 }
 
 type Node struct {
-	Id               TrimmedString    `json:"id" jsonschema:"required,minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Unique identifier for this node. Must start with a letter or underscore, can contain alphanumeric characters, underscores, and hyphens. Cannot end with a hyphen."`
-	InfrastructureId TrimmedString    `json:"infrastructure_id" jsonschema:"required,minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Reference to an entry in the 'infrastructure' array."`
-	Properties       NodeProperties   `json:"properties" jsonschema:"required" jsonschema_description:"General technical configuration of the node."`
-	RemoteAccess     NodeRemoteAccess `json:"remote_access" jsonschema:"required" jsonschema_description:"Access configuration for the node."`
-	DNS              *NodeDNS         `json:"dns,omitempty" jsonschema_description:"DNS configuration for this node."`
+	Id               TrimmedString            `json:"id" jsonschema:"required,minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Unique identifier for this node. Must start with a letter or underscore, can contain alphanumeric characters, underscores, and hyphens. Cannot end with a hyphen."`
+	InfrastructureId TrimmedString            `json:"infrastructure_id,omitempty" jsonschema:"minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Reference to an entry in the 'infrastructure' array. Required unless set in 'defaults.node'."`
+	Properties       NodeProperties           `json:"properties,omitzero" jsonschema_description:"General technical configuration of the node."`
+	RemoteAccess     NodeRemoteAccess         `json:"remote_access,omitzero" jsonschema_description:"Access configuration for the node."`
+	DNS              *NodeDNS                 `json:"dns,omitempty" jsonschema_description:"DNS configuration for this node."`
+	Network          *NodeNetwork             `json:"network,omitempty" jsonschema_description:"VPC, subnet and public IP address of the node, and the CIDR blocks allowed to connect to it."`
+	Schedule         *NodeSchedule            `json:"schedule,omitempty" jsonschema_description:"When the node is started and stopped, e.g. to stop it at night and over weekends. Devcontainers are unreachable while their node is stopped."`
+	Tags             map[string]TrimmedString `json:"tags,omitempty" jsonschema_description:"Tags of the resources of this node, e.g. for cost allocation. Merged with the tags of the levels above, whose values are overridden. Keys are at most 128 characters long and cannot start with 'aws:', values at most 256 characters."`
 }
@@ -0,0 +1,78 @@
+package schema
+
+// validateNodeProperties checks the combinations of node properties: an AMI must declare its architecture,
+// which must match the architecture of the instance type in the catalog, a KMS key requires encryption,
+// and spot options require the spot market.
+func validateNodeProperties(root *DenvclustrRoot, catalog Catalog, c *collector) {
+	providers := make(map[string]Provider)
+	for _, infrastructure := range root.Infrastructure {
+		providers[string(infrastructure.Id)] = infrastructure.Provider
+	}
+
+	for i, node := range root.Nodes {
+		properties := node.Properties
+		p := pointer("nodes", i, "properties")
+
+		if properties.AMI != "" && properties.Architecture == "" {
+			c.add(RuleMissingField, p+pointer("architecture"),
+				"node %q: architecture must be set with ami, so the AMI can be checked against instance type %q", node.Id, properties.InstanceType,
+			)
+		}
+
+		if properties.Architecture != "" {
+			provider, ok := providers[string(node.InfrastructureId)]
+			if instanceType, known := catalog.InstanceType(provider, string(properties.InstanceType)); ok && known && instanceType.Architecture != string(properties.Architecture) {
+				subject := "architecture"
+				if properties.AMI != "" {
+					subject = "AMI " + string(properties.AMI)
+				}
+				c.add(RuleArchitectureMismatch, p+pointer("architecture"),
+					"node %q: %s is %s, but instance type %q is %s", node.Id, subject, properties.Architecture, properties.InstanceType, instanceType.Architecture,
+				)
+			}
+		}
+
+		if volume := properties.RootVolume; volume != nil && volume.KmsKeyId != "" && (volume.Encrypted == nil || !*volume.Encrypted) {
+			c.add(RuleConflictingFields, p+pointer("root_volume", "kms_key_id"),
+				"node %q: root_volume.kms_key_id requires root_volume.encrypted to be true", node.Id,
+			)
+		}
+
+		validateMarket(node, i, c)
+	}
+}
+
+// validateMarket checks that spot options are only set for spot nodes, and that spot nodes use no setting
+// that does not survive an interruption.
+func validateMarket(node *Node, i int, c *collector) {
+	properties := node.Properties
+	p := pointer("nodes", i, "properties")
+
+	if properties.MarketType() != MarketSpot {
+		for _, option := range []struct {
+			name string
+			set  bool
+		}{
+			{"max_price", properties.MaxPrice != nil},
+			{"interruption_behavior", properties.InterruptionBehavior != ""},
+		} {
+			if option.set {
+				c.add(RuleConflictingFields, p+pointer(option.name), "node %q: %s requires market to be %q", node.Id, option.name, MarketSpot)
+			}
+		}
+		return
+	}
+
+	// The public IP address of an interrupted node changes when it is started again, so its DNS records would be stale
+	if node.DNS != nil {
+		c.add(RuleConflictingFields, p+pointer("market"),
+			"node %q: spot nodes cannot use dns, which does not follow the public IP address of the node across interruptions", node.Id,
+		)
+	}
+	// A terminated node loses the workspaces of its devcontainers, and cannot be started again by a schedule
+	if properties.SpotInterruptionBehavior() == InterruptionBehaviorTerminate {
+		c.add(RuleConflictingFields, p+pointer("interruption_behavior"),
+			"node %q: the workspaces of the devcontainers would be deleted when the node is interrupted, use stop to keep them", node.Id,
+		)
+	}
+}
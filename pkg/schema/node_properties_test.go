@@ -0,0 +1,146 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateNodeProperties(t *testing.T) {
+	const base = `name: graviton-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - id: node1
+    infrastructure_id: aws
+    properties: %s
+    remote_access: {public_ssh_key: ~/.ssh/id.pub}
+devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}}
+`
+
+	t.Run("valid properties", func(t *testing.T) {
+		root, err := Parse([]byte(fmt.Sprintf(base,
+			`{instance_type: m7g.large, architecture: arm64, ami: ami-0123456789abcdef0, root_volume: {size: 100, encrypted: true, kms_key_id: alias/dev}, require_imdsv2: true}`,
+		)), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		properties := root.Nodes[0].Properties
+		assert.Equal(t, ArchitectureArm64, properties.Architecture)
+		assert.Equal(t, TrimmedString("ami-0123456789abcdef0"), properties.AMI)
+		require.NotNil(t, properties.RootVolume)
+		assert.Equal(t, 100, *properties.RootVolume.Size)
+		assert.True(t, *properties.RootVolume.Encrypted)
+		assert.True(t, *properties.RequireIMDSv2)
+	})
+
+	t.Run("explicit false wins over defaults", func(t *testing.T) {
+		root, err := Parse([]byte(`name: cluster
+defaults:
+  node:
+    properties: {require_imdsv2: true, root_volume: {size: 50, encrypted: true}}
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large, require_imdsv2: false, root_volume: {encrypted: false}}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}}
+`), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		properties := root.Nodes[0].Properties
+		assert.False(t, *properties.RequireIMDSv2)
+		assert.False(t, *properties.RootVolume.Encrypted)
+		assert.Equal(t, 50, *properties.RootVolume.Size)
+	})
+
+	tests := []struct {
+		name       string
+		properties string
+		code       string
+		pointer    string
+		message    string
+	}{
+		{"arm64 AMI with x86 instance type", `{instance_type: t3.large, architecture: arm64, ami: ami-0123456789abcdef0}`, RuleArchitectureMismatch,
+			"/nodes/0/properties/architecture", `node "node1": AMI ami-0123456789abcdef0 is arm64, but instance type "t3.large" is x86_64`},
+		{"x86 architecture with Graviton instance type", `{instance_type: m7g.large, architecture: x86_64}`, RuleArchitectureMismatch,
+			"/nodes/0/properties/architecture", `node "node1": architecture is x86_64, but instance type "m7g.large" is arm64`},
+		{"AMI without architecture", `{instance_type: t3.large, ami: ami-0123456789abcdef0}`, RuleMissingField,
+			"/nodes/0/properties/architecture", `node "node1": architecture must be set with ami, so the AMI can be checked against instance type "t3.large"`},
+		{"KMS key without encryption", `{instance_type: t3.large, root_volume: {kms_key_id: alias/dev}}`, RuleConflictingFields,
+			"/nodes/0/properties/root_volume/kms_key_id", `node "node1": root_volume.kms_key_id requires root_volume.encrypted to be true`},
+		{"invalid AMI", `{instance_type: t3.large, architecture: x86_64, ami: ubuntu-24.04}`, RuleSchemaPrefix + "pattern",
+			"/nodes/0/properties/ami", "'ubuntu-24.04' does not match pattern '^ami-([0-9a-f]{8}|[0-9a-f]{17})$'"},
+		{"root volume too small", `{instance_type: t3.large, root_volume: {size: 4}}`, RuleSchemaPrefix + "minimum",
+			"/nodes/0/properties/root_volume/size", "minimum: got 4, want 8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate([]byte(fmt.Sprintf(base, tt.properties)), WithFormat(FormatYAML))
+			require.NoError(t, err)
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.code, errs[0].Code)
+			assert.Equal(t, tt.pointer, errs[0].Pointer)
+			assert.Equal(t, tt.message, errs[0].Message)
+		})
+	}
+}
+
+func TestValidateMarket(t *testing.T) {
+	const base = `name: spot-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - id: node1
+    infrastructure_id: aws
+    properties: %s
+    remote_access: {public_ssh_key: ~/.ssh/id.pub}
+    %s
+devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}}
+`
+
+	t.Run("spot node", func(t *testing.T) {
+		root, err := Parse([]byte(fmt.Sprintf(base, `{instance_type: t3.large, market: spot, max_price: 0.05}`, "")), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		properties := root.Nodes[0].Properties
+		assert.Equal(t, MarketSpot, properties.MarketType())
+		assert.Equal(t, 0.05, *properties.MaxPrice)
+		assert.Equal(t, InterruptionBehaviorStop, properties.SpotInterruptionBehavior())
+	})
+
+	tests := []struct {
+		name       string
+		properties string
+		extra      string
+		code       string
+		pointer    string
+		message    string
+	}{
+		{"max price without spot", `{instance_type: t3.large, max_price: 0.05}`, "", RuleConflictingFields,
+			"/nodes/0/properties/max_price", `node "node1": max_price requires market to be "spot"`},
+		{"interruption behavior on demand", `{instance_type: t3.large, market: on_demand, interruption_behavior: stop}`, "", RuleConflictingFields,
+			"/nodes/0/properties/interruption_behavior", `node "node1": interruption_behavior requires market to be "spot"`},
+		{"terminated on interruption", `{instance_type: t3.large, market: spot, interruption_behavior: terminate}`, "", RuleConflictingFields,
+			"/nodes/0/properties/interruption_behavior", `node "node1": the workspaces of the devcontainers would be deleted when the node is interrupted, use stop to keep them`},
+		{"spot with DNS", `{instance_type: t3.large, market: spot}`, "dns: {high_level_domain: dev.example.com}", RuleConflictingFields,
+			"/nodes/0/properties/market", `node "node1": spot nodes cannot use dns, which does not follow the public IP address of the node across interruptions`},
+		{"negative max price", `{instance_type: t3.large, market: spot, max_price: -1}`, "", RuleSchemaPrefix + "exclusiveMinimum",
+			"/nodes/0/properties/max_price", "exclusiveMinimum: got -1, want 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate([]byte(fmt.Sprintf(base, tt.properties, tt.extra)), WithFormat(FormatYAML))
+			require.NoError(t, err)
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.code, errs[0].Code)
+			assert.Equal(t, tt.pointer, errs[0].Pointer)
+			assert.Equal(t, tt.message, errs[0].Message)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Option configures how Parse reads a denvclustr file.
+type Option func(*parseOptions)
+
+type parseOptions struct {
+	format          Format
+	variables       map[string]string
+	lookupEnv       func(string) (string, bool)
+	overlays        []overlay
+	baseDir         string
+	path            string
+	raw             bool
+	catalog         Catalog
+	checkPublicKeys bool
+}
+
+func newParseOptions(opts []Option) *parseOptions {
+	options := &parseOptions{format: FormatJSON, lookupEnv: os.LookupEnv, baseDir: ".", catalog: DefaultCatalog()}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithFormat sets the format of the data passed to Parse. JSON is assumed by default.
+func WithFormat(format Format) Option {
+	return func(o *parseOptions) {
+		o.format = format
+	}
+}
+
+// WithVariables sets values of variables declared in the variables block, overriding their defaults.
+// Calling it several times merges the values, later calls taking precedence.
+func WithVariables(values map[string]string) Option {
+	return func(o *parseOptions) {
+		if o.variables == nil {
+			o.variables = make(map[string]string)
+		}
+		for name, value := range values {
+			o.variables[name] = value
+		}
+	}
+}
+
+// WithLookupEnv sets the function used to resolve "${env.NAME}" references. os.LookupEnv is used by default.
+func WithLookupEnv(lookupEnv func(string) (string, bool)) Option {
+	return func(o *parseOptions) {
+		o.lookupEnv = lookupEnv
+	}
+}
+
+// WithBaseDir sets the directory the paths of the include list and the public SSH key paths are
+// relative to, usually the directory of the parsed file. The current directory is used by default.
+func WithBaseDir(dir string) Option {
+	return func(o *parseOptions) {
+		o.baseDir = dir
+	}
+}
+
+// WithPath sets the path of the parsed file. Its includes and public SSH keys are relative to its directory, as with
+// WithBaseDir, and the file itself is never included, e.g. by an include pattern matching every file of the directory.
+func WithPath(path string) Option {
+	return func(o *parseOptions) {
+		o.path = path
+		o.baseDir = filepath.Dir(path)
+	}
+}
+
+// WithOverlay merges an overlay file onto the parsed data before it is validated.
+// Entries of the infrastructure, nodes and devcontainers arrays are matched by id and
+// patched following RFC 7386 (JSON merge patch), as is the rest of the document.
+// The name identifies the overlay in validation errors, and the public SSH key paths it sets are
+// relative to the directory of the name, usually its path. Overlays are applied in order.
+func WithOverlay(name string, data []byte, format Format) Option {
+	return func(o *parseOptions) {
+		o.overlays = append(o.overlays, overlay{name: name, data: data, format: format})
+	}
+}
+
+// WithRaw makes Parse return the model as written, without the defaults applied by Postprocess.
+func WithRaw() Option {
+	return func(o *parseOptions) {
+		o.raw = true
+	}
+}
+
+// WithCatalog checks regions and instance types against a catalog, e.g. read with ParseCatalog.
+// Its regions and instance types are added to those of the embedded catalog, which is used by default.
+func WithCatalog(catalog Catalog) Option {
+	return func(o *parseOptions) {
+		o.catalog = DefaultCatalog().Override(catalog)
+	}
+}
+
+// WithPublicKeyCheck checks that the public SSH key files of the nodes and devcontainers exist and are
+// OpenSSH public keys, as read by ReadPublicKey. The files are not read by default.
+func WithPublicKeyCheck() Option {
+	return func(o *parseOptions) {
+		o.checkPublicKeys = true
+	}
+}
@@ -0,0 +1,180 @@
+package schema
+
+import (
+	"errors"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergedByIdKeys lists the top-level arrays whose entries are merged by id when applying an overlay.
+var mergedByIdKeys = map[string]bool{
+	"infrastructure": true,
+	"nodes":          true,
+	"devcontainers":  true,
+}
+
+// overlay is a file patching the parsed document, e.g. "denvclustr.staging.json".
+type overlay struct {
+	name   string
+	data   []byte
+	format Format
+}
+
+// applyOverlays decodes the overlays and merges them onto the document in order.
+// Problems in an overlay are reported as ValidationErrors located in the overlay file.
+func applyOverlays(doc *document, overlays []overlay) (ValidationErrors, error) {
+	for _, o := range overlays {
+		patch, err := decodeDocument(o.data, o.format)
+		if err != nil {
+			var syntaxErr *ValidationError
+			if errors.As(err, &syntaxErr) {
+				syntaxErr.File = o.name
+				return ValidationErrors{syntaxErr}, nil
+			}
+			return nil, err
+		}
+		patch.markOrigin(patch.root, o.name)
+
+		if patch.root.Kind != yaml.MappingNode {
+			errs := ValidationErrors{{Code: RuleInvalidOverlay, Severity: SeverityError, Message: "overlay must contain an object"}}
+			patch.locateErrors(errs)
+			return errs, nil
+		}
+
+		if _, value := childNode(patch.root, includeKey); value != nil {
+			errs := ValidationErrors{{Code: RuleInvalidOverlay, Pointer: pointer(includeKey), Severity: SeverityError, Message: "overlays cannot include other files"}}
+			patch.locateErrors(errs)
+			return errs, nil
+		}
+
+		if errs, err := upgradeDocument(patch); errs != nil || err != nil {
+			return errs, err
+		}
+
+		doc.mergeOverlay(patch)
+	}
+	return nil, nil
+}
+
+// mergeOverlay merges an overlay onto the document following the JSON merge patch rules
+// of RFC 7386, except for the entries of the infrastructure, nodes and devcontainers arrays,
+// which are matched by id: an entry is merge-patched onto the entry with the same id,
+// or appended when the document has no such entry.
+func (d *document) mergeOverlay(patch *document) {
+	d.importOrigins(patch)
+	if d.root.Kind != yaml.MappingNode {
+		d.root = d.mergePatch(nil, patch.root)
+		return
+	}
+
+	for i := 0; i+1 < len(patch.root.Content); i += 2 {
+		key, value := patch.root.Content[i], resolveAlias(patch.root.Content[i+1])
+		_, target := childNode(d.root, key.Value)
+		if mergedByIdKeys[key.Value] && target != nil && target.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode {
+			d.mergeEntries(target, value)
+			continue
+		}
+		d.mergeMember(d.root, key, value)
+	}
+}
+
+// mergeEntries merges the entries of a patch array onto the entries of a target array with the same id.
+func (d *document) mergeEntries(target, patch *yaml.Node) {
+	for _, entry := range patch.Content {
+		entry = resolveAlias(entry)
+		if existing := findEntry(target, entry); existing != nil {
+			d.mergePatch(existing, entry)
+			continue
+		}
+		target.Content = append(target.Content, d.mergePatch(nil, entry))
+	}
+}
+
+// findEntry returns the entry of a sequence with the same id as the given entry.
+func findEntry(sequence, entry *yaml.Node) *yaml.Node {
+	_, id := childNode(entry, "id")
+	if id == nil || id.Kind != yaml.ScalarNode {
+		return nil
+	}
+	for _, candidate := range sequence.Content {
+		if _, candidateId := childNode(candidate, "id"); candidateId != nil && candidateId.Kind == yaml.ScalarNode && candidateId.Value == id.Value {
+			return resolveAlias(candidate)
+		}
+	}
+	return nil
+}
+
+// mergePatch applies a merge patch onto a target node and returns the result.
+// The target is modified in place when both nodes are objects. A new object
+// comes from the same file as the patch, so errors in it are located in the patch.
+func (d *document) mergePatch(target, patch *yaml.Node) *yaml.Node {
+	patch = resolveAlias(patch)
+	if patch.Kind != yaml.MappingNode {
+		return patch
+	}
+
+	target = resolveAlias(target)
+	if target == nil || target.Kind != yaml.MappingNode {
+		target = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: patch.Line, Column: patch.Column}
+		if origin, ok := d.origins[patch]; ok {
+			d.origins[target] = origin
+		}
+	}
+	for i := 0; i+1 < len(patch.Content); i += 2 {
+		d.mergeMember(target, patch.Content[i], resolveAlias(patch.Content[i+1]))
+	}
+	return target
+}
+
+// mergeMember applies a single member of a merge patch onto a mapping node.
+// A null value removes the member, an object is merged recursively and any other value replaces it.
+func (d *document) mergeMember(target, key, value *yaml.Node) {
+	index := -1
+	for i := 0; i+1 < len(target.Content); i += 2 {
+		if target.Content[i].Value == key.Value {
+			index = i
+			break
+		}
+	}
+
+	switch {
+	case value.Kind == yaml.ScalarNode && value.Tag == "!!null":
+		if index >= 0 {
+			target.Content = append(target.Content[:index], target.Content[index+2:]...)
+		}
+	case index >= 0 && sameScalar(resolveAlias(target.Content[index+1]), value):
+		// Unchanged values, such as the id of a merged entry, stay located in the patched file
+	case index >= 0 && value.Kind == yaml.MappingNode && resolveAlias(target.Content[index+1]).Kind == yaml.MappingNode:
+		d.mergePatch(target.Content[index+1], value)
+	case index >= 0:
+		// The key of the overlay is kept, so errors in the new value are located in the overlay
+		target.Content[index], target.Content[index+1] = key, d.mergePatch(nil, value)
+	default:
+		target.Content = append(target.Content, key, d.mergePatch(nil, value))
+	}
+}
+
+func sameScalar(a, b *yaml.Node) bool {
+	return a.Kind == yaml.ScalarNode && b.Kind == yaml.ScalarNode && a.Tag == b.Tag && a.Value == b.Value
+}
+
+// markOrigin records that a node and all its descendants come from the named file.
+func (d *document) markOrigin(node *yaml.Node, name string) {
+	if d.origins == nil {
+		d.origins = make(map[*yaml.Node]string)
+	}
+	d.origins[node] = name
+	for _, child := range node.Content {
+		d.markOrigin(child, name)
+	}
+}
+
+// importOrigins copies the origins recorded by another document, whose nodes are merged into this one.
+func (d *document) importOrigins(other *document) {
+	if d.origins == nil {
+		d.origins = make(map[*yaml.Node]string)
+	}
+	for node, name := range other.origins {
+		d.origins[node] = name
+	}
+}
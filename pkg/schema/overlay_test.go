@@ -0,0 +1,156 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWithOverlay(t *testing.T) {
+	base, err := testDataFS.ReadFile("testdata/valid_complete.json")
+	require.NoError(t, err, "Failed to read test file")
+
+	t.Run("entries are merged by id", func(t *testing.T) {
+		overlay, err := testDataFS.ReadFile("testdata/overlay_staging.yaml")
+		require.NoError(t, err, "Failed to read test file")
+
+		root, err := Parse(base, WithOverlay("overlay_staging.yaml", overlay, FormatYAML))
+		require.NoError(t, err)
+
+		assert.Equal(t, TrimmedString("staging-cluster"), root.Name)
+
+		require.Len(t, root.Infrastructure, 2)
+		assert.Equal(t, TrimmedString("us-west-2"), root.Infrastructure[0].Region)
+		assert.Equal(t, TrimmedString("eu-central-1"), root.Infrastructure[1].Region)
+		assert.Equal(t, KindVm, root.Infrastructure[1].Kind)
+
+		require.Len(t, root.Nodes, 2)
+		assert.Equal(t, TrimmedString("t3.large"), root.Nodes[0].Properties.InstanceType)
+		assert.Equal(t, TrimmedString("~/.ssh/id_rsa.pub"), root.Nodes[0].RemoteAccess.PublicSSHKey)
+		assert.Nil(t, root.Nodes[0].DNS)
+
+		require.Len(t, root.Devcontainers, 3)
+		assert.Equal(t, TrimmedString("https://github.com/example/web-app.git"), root.Devcontainers[0].Source.URL)
+		assert.Equal(t, TrimmedString("staging"), root.Devcontainers[0].Source.Branch)
+		assert.Equal(t, TrimmedString("devcontainer-api"), root.Devcontainers[1].Id)
+		assert.Equal(t, TrimmedString("devcontainer-docs"), root.Devcontainers[2].Id)
+		assert.Equal(t, TrimmedString(""), root.Devcontainers[2].Source.Branch)
+	})
+
+	t.Run("errors are located in the overlay", func(t *testing.T) {
+		overlay, err := testDataFS.ReadFile("testdata/overlay_invalid.yaml")
+		require.NoError(t, err, "Failed to read test file")
+
+		_, err = Parse(base, WithOverlay("overlay_invalid.yaml", overlay, FormatYAML))
+
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "schema/enum", errs[0].Code)
+		assert.Equal(t, "/infrastructure/0/kind", errs[0].Pointer)
+		assert.Equal(t, "overlay_invalid.yaml", errs[0].File)
+		assert.Equal(t, 3, errs[0].Line)
+		assert.Equal(t, 5, errs[0].Column)
+	})
+
+	t.Run("syntax errors are located in the overlay", func(t *testing.T) {
+		_, err := Parse(base, WithOverlay("staging.json", []byte("{\n  \"name\": \n}"), FormatJSON))
+
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 1)
+		assert.Equal(t, RuleSyntax, errs[0].Code)
+		assert.Equal(t, "staging.json", errs[0].File)
+		assert.Equal(t, 3, errs[0].Line)
+	})
+
+	t.Run("errors in added entries are located in the overlay", func(t *testing.T) {
+		overlay := []byte(`nodes:
+  - id: aws-node3
+    infrastructure_id: aws-infrastructure1
+    properties:
+      instance_type: t3.small
+    remote_access:
+      public_ssh_key: ~/.ssh/id_rsa.pub
+`)
+		_, err := Parse(base, WithOverlay("staging.yaml", overlay, FormatYAML))
+
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 1)
+		assert.Equal(t, RuleUnreferencedNode, errs[0].Code)
+		assert.Equal(t, "/nodes/2", errs[0].Pointer)
+		assert.Equal(t, "staging.yaml", errs[0].File)
+		assert.Equal(t, 2, errs[0].Line)
+		assert.Equal(t, 5, errs[0].Column)
+	})
+
+	t.Run("overlay must be an object", func(t *testing.T) {
+		_, err := Parse(base, WithOverlay("staging.json", []byte(`[]`), FormatJSON))
+
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 1)
+		assert.Equal(t, RuleInvalidOverlay, errs[0].Code)
+		assert.Equal(t, "staging.json", errs[0].File)
+	})
+}
+
+func TestMarshal(t *testing.T) {
+	data, err := testDataFS.ReadFile("testdata/valid_minimal.yaml")
+	require.NoError(t, err, "Failed to read test file")
+
+	root, err := Parse(data, WithFormat(FormatYAML))
+	require.NoError(t, err)
+
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			encoded, err := Marshal(root, format)
+			require.NoError(t, err)
+
+			decoded, err := Parse(encoded, WithFormat(format))
+			require.NoError(t, err)
+			assert.Equal(t, root, decoded)
+		})
+	}
+}
+
+func TestMarshalEffective(t *testing.T) {
+	lookupEnv := func(name string) (string, bool) { return "/home/dev", name == "HOME" }
+
+	tests := []struct {
+		name     string
+		filename string
+		opts     []Option
+	}{
+		{"variables are resolved", "variables.yaml", []Option{WithLookupEnv(lookupEnv), WithVariables(map[string]string{"region": "eu-west-1"})}},
+		{"defaults are applied", "defaults_block.yaml", nil},
+		{"raw model", "defaults.yaml", []Option{WithRaw()}},
+		{"raw model with a defaults block", "defaults_block.yaml", []Option{WithRaw()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := testDataFS.ReadFile("testdata/" + tt.filename)
+			require.NoError(t, err, "Failed to read test file")
+
+			root, err := Parse(data, append([]Option{WithFormat(FormatYAML)}, tt.opts...)...)
+			require.NoError(t, err)
+
+			for _, format := range []Format{FormatJSON, FormatYAML} {
+				encoded, err := MarshalEffective(root, format)
+				require.NoError(t, err)
+				assert.NotContains(t, string(encoded), "variables")
+				assert.NotContains(t, string(encoded), `""`)
+
+				// The effective configuration is read back without any option
+				decoded, err := Parse(encoded, WithFormat(format), WithRaw())
+				require.NoError(t, err, string(encoded))
+				expected := *root
+				expected.Variables = nil
+				assert.Equal(t, &expected, decoded)
+			}
+		})
+	}
+}
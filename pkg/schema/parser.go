@@ -2,32 +2,220 @@ package schema
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Parse deserializes the provided raw data into a DenvclustrRoot structure,
-// and validates the deserialized data. It returns the validated DenvclustrRoot or an error.
-func Parse(data []byte) (*DenvclustrRoot, error) {
+// validates the deserialized data and applies the defaults of Postprocess.
+// It returns the validated DenvclustrRoot or an error.
+// The data is read as JSON unless another format is selected with WithFormat,
+// and the model is returned as written, without defaults, with WithRaw.
+//
+// Every problem found in the data is collected in a single pass: when the data is invalid,
+// the returned error is a ValidationErrors list describing all of them.
+func Parse(data []byte, opts ...Option) (*DenvclustrRoot, error) {
+	root, errs, err := parse(data, newParseOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	if errs.HasErrors() {
+		return nil, errs
+	}
+	return root, nil
+}
 
-	var rawRoot any
-	if err := json.Unmarshal(data, &rawRoot); err != nil {
-		return nil, fmt.Errorf("unmarshal schema: %w", err)
+// parse runs the parsing pipeline. Problems in the data are returned as ValidationErrors,
+// while the error result is reserved for failures unrelated to the data itself.
+func parse(data []byte, options *parseOptions) (*DenvclustrRoot, ValidationErrors, error) {
+	doc, err := decodeDocument(data, options.format)
+	if err != nil {
+		var syntaxErr *ValidationError
+		if errors.As(err, &syntaxErr) {
+			return nil, ValidationErrors{syntaxErr}, nil
+		}
+		return nil, nil, err
 	}
 
-	if err := validateSchema(rawRoot); err != nil {
-		return nil, err
+	if errs, err := upgradeDocument(doc); errs != nil || err != nil {
+		return nil, errs, err
 	}
 
-	// Deserialize the data
-	root, err := deserializeDenvclustrFile(data)
+	// Fragments are included before overlays are merged, so overlays can patch their entries.
+	// Include errors are located right away, since the include list is removed from the document.
+	errs, err := expandIncludes(doc, options)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if errs.HasErrors() {
+		errs.sortByPosition()
+		return nil, errs, nil
 	}
 
-	// Validate the deserialized data
-	if err := validateDeserialized(root); err != nil {
-		return nil, err
+	// Overlays are merged before variables are resolved, so they can change variable defaults
+	if overlayErrs, err := applyOverlays(doc, options.overlays); overlayErrs != nil || err != nil {
+		return nil, append(errs, overlayErrs...), err
 	}
 
-	return root, nil
+	// Variables are resolved once includes and overlays are merged, so the schema validates the values actually used
+	variableErrs := resolveVariables(doc, options)
+	errs = append(errs, variableErrs...)
+
+	// Public SSH key paths are resolved once variables are, since a variable may hold the path
+	resolvePublicKeyPaths(doc, options.baseDir)
+
+	rawRoot, err := doc.value()
+	if err != nil {
+		return nil, ValidationErrors{{Code: RuleSyntax, Severity: SeverityError, Message: err.Error()}}, nil
+	}
+
+	schemaErrs, err := validateSchema(rawRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	errs = append(errs, schemaErrs...)
+
+	// Deserialize the data. Values of the wrong type are left out, so the semantic checks
+	// still run on the rest of a document that does not match the JSON schema.
+	jsonData, err := json.Marshal(withoutMistypedValues(rawRoot, schemaErrs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("convert document: %w", err)
+	}
+	root, err := deserializeDenvclustrFile(jsonData)
+	if err != nil {
+		if schemaErrs.HasErrors() {
+			doc.locateErrors(errs)
+			errs.sortByPosition()
+			return nil, errs, nil
+		}
+		return nil, nil, fmt.Errorf("deserialize document: %w", err)
+	}
+
+	// Values may be set in the defaults block, so the semantic checks run on the normalized model.
+	// The raw model is deserialized separately, since Postprocess changes the model in place.
+	normalized := root
+	if options.raw {
+		if normalized, err = deserializeDenvclustrFile(jsonData); err != nil {
+			return nil, nil, fmt.Errorf("deserialize document: %w", err)
+		}
+	}
+	origins := postprocess(normalized)
+
+	// Validate the deserialized data. Values already reported by the schema, or left unresolved, are not reported twice.
+	// Errors of expanded replicas are reported once, on the devcontainer they are defined by.
+	remap := func(p string) string { return remapReplicaPointer(p, origins) }
+	semanticErrs := validateDeserialized(normalized, options.catalog, func(p string) string { return doc.origin(remap(p)) }, origins)
+	if options.checkPublicKeys {
+		c := &collector{replicas: origins}
+		validatePublicKeys(normalized, c)
+		semanticErrs = append(semanticErrs, c.errs...)
+	}
+	for _, err := range semanticErrs {
+		err.Pointer = remap(err.Pointer)
+	}
+	reported := append(append(ValidationErrors{}, schemaErrs...), variableErrs...)
+	errs = append(errs, withoutOverlaps(withoutRepeats(semanticErrs), reported)...)
+	doc.locateErrors(errs)
+	errs.sortByPosition()
+
+	return root, errs, nil
+}
+
+// upgradeDocument checks the version of a document and migrates it in memory to the
+// current version, so it is validated against the current schema.
+func upgradeDocument(doc *document) (ValidationErrors, error) {
+	if doc.root.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	version, _, err := documentVersion(doc.root)
+	if err != nil {
+		errs := ValidationErrors{{Code: RuleUnsupportedVersion, Pointer: pointer(versionKey), Severity: SeverityError, Message: err.Error()}}
+		doc.locateErrors(errs)
+		return errs, nil
+	}
+	return nil, migrateDocument(doc.root, version)
+}
+
+// withoutMistypedValues returns a copy of the generic representation of a document where the values
+// reported with a schema type error are removed, so the rest of it can be deserialized. Array elements
+// are replaced with an empty object instead, which keeps the index of the following elements.
+func withoutMistypedValues(value any, schemaErrs ValidationErrors) any {
+	var pointers []string
+	for _, err := range schemaErrs {
+		if err.Code == RuleSchemaPrefix+"type" {
+			pointers = append(pointers, err.Pointer)
+		}
+	}
+	return pruneValue(value, "", pointers)
+}
+
+func pruneValue(value any, p string, pointers []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, member := range v {
+			memberPointer := p + pointer(key)
+			if !slices.Contains(pointers, memberPointer) {
+				result[key] = pruneValue(member, memberPointer, pointers)
+			}
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, element := range v {
+			elementPointer := p + pointer(i)
+			if slices.Contains(pointers, elementPointer) {
+				result[i] = map[string]any{}
+			} else {
+				result[i] = pruneValue(element, elementPointer, pointers)
+			}
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// withoutOverlaps returns the semantic errors whose value neither contains nor is contained
+// in a value already reported, e.g. a missing field already reported as required by the schema.
+func withoutOverlaps(errs, schemaErrs ValidationErrors) ValidationErrors {
+	var result ValidationErrors
+	for _, err := range errs {
+		overlaps := false
+		for _, schemaErr := range schemaErrs {
+			if containsPointer(err.Pointer, schemaErr.Pointer) || containsPointer(schemaErr.Pointer, err.Pointer) {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// withoutRepeats returns the errors without those reported again for the same rule and value.
+func withoutRepeats(errs ValidationErrors) ValidationErrors {
+	type key struct{ code, pointer string }
+	seen := make(map[key]bool)
+	var result ValidationErrors
+	for _, err := range errs {
+		k := key{err.Code, err.Pointer}
+		if !seen[k] {
+			seen[k] = true
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// containsPointer reports whether the value addressed by child is the value addressed by parent or nested in it.
+func containsPointer(parent, child string) bool {
+	return child == parent || strings.HasPrefix(child, parent+"/")
 }
@@ -2,12 +2,14 @@ package schema
 
 import (
 	"embed"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-//go:embed testdata/*.json
+//go:embed testdata/*.json testdata/*.jsonc testdata/*.yaml testdata/*.yml
 var testDataFS embed.FS
 
 func TestParse(t *testing.T) {
@@ -93,6 +95,34 @@ func TestParse(t *testing.T) {
 			filename:    "valid_complete.json",
 			expectError: false,
 		},
+		{
+			name:          "Unsupported version",
+			filename:      "unsupported_version.json",
+			expectError:   true,
+			errorContains: "unsupported version \"42\"",
+		},
+		{
+			name:        "Valid minimal YAML config",
+			filename:    "valid_minimal.yaml",
+			expectError: false,
+		},
+		{
+			name:        "Valid minimal JSONC config",
+			filename:    "valid_minimal.jsonc",
+			expectError: false,
+		},
+		{
+			name:          "Invalid YAML syntax",
+			filename:      "invalid_yaml_syntax.yaml",
+			expectError:   true,
+			errorContains: "decode yaml document",
+		},
+		{
+			name:          "Missing required field in YAML - nodes",
+			filename:      "missing_required.yml",
+			expectError:   true,
+			errorContains: "missing property 'nodes'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -102,7 +132,7 @@ func TestParse(t *testing.T) {
 			assert.NoError(t, err, "Failed to read test file")
 
 			// Call the function being tested
-			result, err := Parse(data)
+			result, err := Parse(data, WithFormat(DetectFormat(tt.filename, data)))
 
 			// Check if error behavior matches expected
 			if tt.expectError {
@@ -124,3 +154,231 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+		expected Format
+	}{
+		{"JSON extension", "denvclustr.json", `{"name": "x"}`, FormatJSON},
+		{"JSONC extension", "denvclustr.jsonc", `{"name": "x"}`, FormatJSONC},
+		{"YAML extension", "denvclustr.yaml", `name: x`, FormatYAML},
+		{"YML extension", "DENVCLUSTR.YML", `name: x`, FormatYAML},
+		{"JSON content", "config", `{"name": "x"}`, FormatJSON},
+		{"JSONC content", "config", "// cluster\n{\"name\": \"x\",}", FormatJSONC},
+		{"YAML content", "config", "name: x\n", FormatYAML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectFormat(tt.filename, []byte(tt.data)))
+		})
+	}
+}
+
+func TestParseFormatsProduceSameResult(t *testing.T) {
+	var results []*DenvclustrRoot
+	for _, filename := range []string{"valid_minimal.json", "valid_minimal.jsonc", "valid_minimal.yaml"} {
+		data, err := testDataFS.ReadFile("testdata/" + filename)
+		assert.NoError(t, err, "Failed to read test file")
+
+		result, err := Parse(data, WithFormat(DetectFormat(filename, data)))
+		assert.NoError(t, err, filename)
+		results = append(results, result)
+	}
+
+	assert.Equal(t, results[0], results[1])
+	assert.Equal(t, results[0], results[2])
+}
+
+func TestParseValidationErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		expected ValidationErrors
+	}{
+		{
+			name:     "All semantic errors are collected",
+			filename: "multiple_errors.json",
+			expected: ValidationErrors{
+				{Code: RuleSSHKeyRequired, Pointer: "/devcontainers/0/source/ssh_key", Line: 27, Column: 7, Severity: SeverityError},
+				{Code: RuleUnknownNode, Pointer: "/devcontainers/1/node_id", Line: 33, Column: 7, Severity: SeverityError},
+				{Code: RuleSSHKeyRequired, Pointer: "/devcontainers/2/source/ssh_key", Line: 41, Column: 7, Severity: SeverityError},
+			},
+		},
+		{
+			name:     "All schema errors are collected with YAML positions",
+			filename: "schema_errors.yaml",
+			expected: ValidationErrors{
+				{Code: "schema/enum", Pointer: "/infrastructure/0/kind", Line: 4, Column: 5, Severity: SeverityError},
+				{Code: "schema/minimum", Pointer: "/devcontainers/0/remote_access/ssh/port", Line: 21, Column: 9, Severity: SeverityError},
+			},
+		},
+		{
+			name:     "Semantic errors are collected along with schema errors",
+			filename: "schema_and_semantic_errors.yaml",
+			expected: ValidationErrors{
+				{Code: "schema/enum", Pointer: "/infrastructure/0/kind", Line: 4, Column: 5, Severity: SeverityError},
+				{Code: RuleSSHKeyRequired, Pointer: "/devcontainers/0/source/ssh_key", Line: 17, Column: 5, Severity: SeverityError},
+				{Code: "schema/type", Pointer: "/devcontainers/0/remote_access/ssh/port", Line: 21, Column: 9, Severity: SeverityError},
+				{Code: RuleDuplicateId, Pointer: "/devcontainers/1/id", Line: 22, Column: 5, Severity: SeverityError},
+			},
+		},
+		{
+			name:     "Syntax error position",
+			filename: "invalid_json_syntax.json",
+			expected: ValidationErrors{
+				{Code: RuleSyntax, Line: 8, Column: 7, Severity: SeverityError},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := testDataFS.ReadFile("testdata/" + tt.filename)
+			assert.NoError(t, err, "Failed to read test file")
+
+			_, err = Parse(data, WithFormat(DetectFormat(tt.filename, data)))
+
+			var errs ValidationErrors
+			assert.ErrorAs(t, err, &errs)
+			assert.Len(t, errs, len(tt.expected))
+			for i := range min(len(errs), len(tt.expected)) {
+				assert.NotEmpty(t, errs[i].Message)
+				errs[i].Message = ""
+				assert.Equal(t, tt.expected[i], errs[i])
+			}
+		})
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	data, err := testDataFS.ReadFile("testdata/defaults.yaml")
+	require.NoError(t, err, "Failed to read test file")
+
+	t.Run("defaults are applied", func(t *testing.T) {
+		root, err := Parse(data, WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		port := 8000
+		assert.Equal(t, &DevcontainerRemoteAccess{OpenVsCodeServer: &DevcontainerOpenVSCodeServer{Port: &port}}, root.Devcontainers[0].RemoteAccess)
+		assert.Nil(t, root.Devcontainers[1].RemoteAccess.OpenVsCodeServer)
+		assert.Equal(t, TrimmedString("~/.ssh/id_rsa.pub"), root.Devcontainers[1].RemoteAccess.Ssh.PublicSshKey)
+		assert.Equal(t, TrimmedString("~/.ssh/docs.pub"), root.Devcontainers[2].RemoteAccess.Ssh.PublicSshKey)
+		assert.Equal(t, 2223, *root.Devcontainers[2].RemoteAccess.Ssh.Port)
+	})
+
+	t.Run("raw model is returned as written", func(t *testing.T) {
+		root, err := Parse(data, WithFormat(FormatYAML), WithRaw())
+		require.NoError(t, err)
+
+		assert.Nil(t, root.Devcontainers[0].RemoteAccess)
+		assert.Equal(t, TrimmedString(""), root.Devcontainers[1].RemoteAccess.Ssh.PublicSshKey)
+	})
+}
+
+func TestParseDefaultsBlock(t *testing.T) {
+	data, err := testDataFS.ReadFile("testdata/defaults_block.yaml")
+	require.NoError(t, err, "Failed to read test file")
+
+	t.Run("defaults are merged into every entry", func(t *testing.T) {
+		root, err := Parse(data, WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		assert.Equal(t, TrimmedString("aws"), root.Nodes[0].InfrastructureId)
+		assert.Equal(t, TrimmedString("t3.medium"), root.Nodes[0].Properties.InstanceType)
+		assert.Equal(t, TrimmedString("~/.ssh/team.pub"), root.Nodes[0].RemoteAccess.PublicSSHKey)
+		assert.Equal(t, TrimmedString("aws"), root.Nodes[1].InfrastructureId)
+		assert.Equal(t, TrimmedString("t3.large"), root.Nodes[1].Properties.InstanceType)
+		assert.Equal(t, TrimmedString("~/.ssh/node2.pub"), root.Nodes[1].RemoteAccess.PublicSSHKey)
+
+		web, api := root.Devcontainers[0], root.Devcontainers[1]
+		assert.Equal(t, TrimmedString("node1"), web.NodeId)
+		assert.Equal(t, TrimmedString("main"), web.Source.Branch)
+		assert.Equal(t, TrimmedString(".devcontainer/cloud"), web.Source.DevcontainerPath)
+		assert.Nil(t, web.RemoteAccess.OpenVsCodeServer)
+		assert.Equal(t, TrimmedString("~/.ssh/team.pub"), web.RemoteAccess.Ssh.PublicSshKey)
+
+		assert.Equal(t, TrimmedString("node2"), api.NodeId)
+		assert.Equal(t, TrimmedString("develop"), api.Source.Branch)
+		assert.Equal(t, TrimmedString(".devcontainer/cloud"), api.Source.DevcontainerPath)
+		require.NotNil(t, api.RemoteAccess.OpenVsCodeServer)
+		assert.Equal(t, 8080, *api.RemoteAccess.OpenVsCodeServer.Port)
+		assert.Equal(t, TrimmedString("~/.ssh/node2.pub"), api.RemoteAccess.Ssh.PublicSshKey)
+	})
+
+	t.Run("raw model keeps the entries as written", func(t *testing.T) {
+		root, err := Parse(data, WithFormat(FormatYAML), WithRaw())
+		require.NoError(t, err)
+
+		assert.Equal(t, TrimmedString(""), root.Nodes[0].Properties.InstanceType)
+		assert.Nil(t, root.Devcontainers[0].RemoteAccess)
+	})
+
+	t.Run("values missing from entries and defaults are reported", func(t *testing.T) {
+		data := []byte(`{
+  "name": "cluster",
+  "defaults": {"node": {"infrastructure_id": "aws"}},
+  "infrastructure": [{"id": "aws", "kind": "vm", "provider": "aws", "region": "eu-central-1"}],
+  "nodes": [{"id": "node1", "remote_access": {"public_ssh_key": "~/.ssh/id.pub"}}],
+  "devcontainers": [{"id": "web", "node_id": "node1", "source": {"url": "https://github.com/example/web.git"}}]
+}`)
+
+		errs, err := Validate(data)
+		require.NoError(t, err)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "schema/required", errs[0].Code)
+		assert.Equal(t, "/nodes/0", errs[0].Pointer)
+		assert.Equal(t, 5, errs[0].Line)
+	})
+
+	t.Run("entries are complete without a defaults block", func(t *testing.T) {
+		data := []byte(`{
+  "name": "cluster",
+  "infrastructure": [{"id": "aws", "kind": "vm", "provider": "aws", "region": "eu-central-1"}],
+  "nodes": [{"id": "node1", "infrastructure_id": "aws", "properties": {}, "remote_access": {"public_ssh_key": "~/.ssh/id.pub"}}],
+  "devcontainers": [{"id": "web", "source": {"url": "https://github.com/example/web.git"}}]
+}`)
+
+		errs, err := Validate(data)
+		require.NoError(t, err)
+		require.Len(t, errs, 2)
+		assert.Equal(t, "schema/required", errs[0].Code)
+		assert.Equal(t, "/nodes/0/properties", errs[0].Pointer)
+		assert.Equal(t, "schema/required", errs[1].Code)
+		assert.Equal(t, "/devcontainers/0", errs[1].Pointer)
+	})
+
+	t.Run("defaults cannot set an id", func(t *testing.T) {
+		errs, err := Validate([]byte(`{"name": "cluster", "defaults": {"node": {"id": "node1"}}, "infrastructure": [], "nodes": [], "devcontainers": []}`))
+		require.NoError(t, err)
+		require.NotEmpty(t, errs)
+		assert.Equal(t, "schema/additionalProperties", errs[0].Code)
+		assert.Equal(t, "/defaults/node", errs[0].Pointer)
+	})
+}
+
+func TestParseAndValidateKeepsWarnings(t *testing.T) {
+	const base = `name: interview-cluster
+expires_at: "%s"
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}}
+`
+
+	root, errs, err := ParseAndValidate([]byte(fmt.Sprintf(base, "2000-01-01T00:00:00Z")), WithFormat(FormatYAML))
+	require.NoError(t, err)
+	require.NotNil(t, root)
+	require.Len(t, errs, 1)
+	assert.Equal(t, SeverityWarning, errs[0].Severity)
+
+	root, errs, err = ParseAndValidate([]byte(fmt.Sprintf(base, "2000-01-01")), WithFormat(FormatYAML))
+	require.NoError(t, err)
+	assert.Nil(t, root)
+	assert.True(t, errs.HasErrors())
+}
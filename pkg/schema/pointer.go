@@ -0,0 +1,33 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// pointer builds a JSON pointer (RFC 6901) from object keys and array indexes.
+func pointer(tokens ...any) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(pointerEscaper.Replace(fmt.Sprint(token)))
+	}
+	return sb.String()
+}
+
+// splitPointer returns the unescaped reference tokens of a JSON pointer.
+func splitPointer(p string) []string {
+	if p == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = pointerUnescaper.Replace(token)
+	}
+	return tokens
+}
@@ -0,0 +1,126 @@
+package schema
+
+// First ports assigned to devcontainers that do not set one. Ports already used
+// on the node are skipped, so the assignment only depends on the order of the devcontainers.
+const (
+	firstOpenVSCodeServerPort = 8000
+	firstSSHPort              = 2222
+)
+
+// devcontainerPort is a port claimed by a devcontainer.
+type devcontainerPort struct {
+	access string
+	port   *int
+}
+
+// claimedPorts returns the OpenVSCode Server and SSH ports of a devcontainer, set or not.
+func claimedPorts(devcontainer *Devcontainer) []devcontainerPort {
+	if devcontainer == nil || devcontainer.RemoteAccess == nil {
+		return nil
+	}
+	var ports []devcontainerPort
+	if devcontainer.RemoteAccess.OpenVsCodeServer != nil {
+		ports = append(ports, devcontainerPort{"openvscode_server", devcontainer.RemoteAccess.OpenVsCodeServer.Port})
+	}
+	if devcontainer.RemoteAccess.Ssh != nil {
+		ports = append(ports, devcontainerPort{"ssh", devcontainer.RemoteAccess.Ssh.Port})
+	}
+	return ports
+}
+
+// assignPorts sets the ports the devcontainers left unset, in the order of the devcontainers, to the
+// first ports not used on their node. OpenVSCode Servers of nodes with DNS are reached through their
+// domain and get no port.
+func assignPorts(root *DenvclustrRoot) {
+	nodes := collectNodeMap(root)
+
+	used := make(map[string]map[int]bool)
+	for _, devcontainer := range root.Devcontainers {
+		for _, claimed := range claimedPorts(devcontainer) {
+			if claimed.port == nil {
+				continue
+			}
+			nodeId := string(devcontainer.NodeId)
+			if used[nodeId] == nil {
+				used[nodeId] = make(map[int]bool)
+			}
+			used[nodeId][*claimed.port] = true
+		}
+	}
+
+	next := func(nodeId string, first int) *int {
+		if used[nodeId] == nil {
+			used[nodeId] = make(map[int]bool)
+		}
+		for port := first; port <= maxPort; port++ {
+			if !used[nodeId][port] {
+				used[nodeId][port] = true
+				return &port
+			}
+		}
+		return nil
+	}
+
+	for _, devcontainer := range root.Devcontainers {
+		nodeId := string(devcontainer.NodeId)
+		node, hasNode := nodes[nodeId]
+		for _, claimed := range claimedPorts(devcontainer) {
+			if claimed.port != nil {
+				continue
+			}
+			switch claimed.access {
+			case "openvscode_server":
+				if hasNode && node.DNS != nil {
+					continue
+				}
+				devcontainer.RemoteAccess.OpenVsCodeServer.Port = next(nodeId, firstOpenVSCodeServerPort)
+			case "ssh":
+				devcontainer.RemoteAccess.Ssh.Port = next(nodeId, firstSSHPort)
+			}
+		}
+	}
+}
+
+// validatePorts checks that no port is claimed twice on a node, and that OpenVSCode Servers
+// of nodes with DNS, which are reached through their domain, set no port.
+func validatePorts(root *DenvclustrRoot, c *collector) {
+	nodes := collectNodeMap(root)
+
+	type owner struct {
+		name   string
+		access string
+	}
+	claimedByNode := make(map[string]map[int]owner)
+
+	for i, devcontainer := range root.Devcontainers {
+		id := string(devcontainer.Id)
+		nodeId := string(devcontainer.NodeId)
+		node, ok := nodes[nodeId]
+		if id == "" || !ok {
+			continue
+		}
+
+		for _, claimed := range claimedPorts(devcontainer) {
+			if claimed.port == nil {
+				continue
+			}
+			p := pointer("devcontainers", i, "remote_access", claimed.access, "port")
+
+			if claimed.access == "openvscode_server" && node.DNS != nil {
+				c.add(RulePortWithDNS, p, "%s: openvscode_server.port must be omitted, since node %q is reached through DNS", c.devcontainer(i, devcontainer), nodeId)
+				continue
+			}
+
+			if claimedByNode[nodeId] == nil {
+				claimedByNode[nodeId] = make(map[int]owner)
+			}
+			if first, exists := claimedByNode[nodeId][*claimed.port]; exists {
+				c.add(RulePortConflict, p, "%s: %s port %d on node %q is already used by the %s of %s",
+					c.replica(i, devcontainer), claimed.access, *claimed.port, nodeId, first.access, first.name,
+				)
+				continue
+			}
+			claimedByNode[nodeId][*claimed.port] = owner{c.replica(i, devcontainer), claimed.access}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const portsBase = `name: ports-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+  - {id: node2, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}, dns: {high_level_domain: dev.example.com}}
+devcontainers:
+`
+
+func TestParsePorts(t *testing.T) {
+	t.Run("unset ports are assigned in order", func(t *testing.T) {
+		data := []byte(portsBase + `  - {id: web, node_id: node1, source: {url: "https://github.com/example/web.git"}, remote_access: {openvscode_server: {}, ssh: {}}}
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}, remote_access: {openvscode_server: {port: 8001}, ssh: {port: 2222}}}
+  - {id: docs, node_id: node1, source: {url: "https://github.com/example/docs.git"}, remote_access: {openvscode_server: {}, ssh: {}}}
+  - {id: tools, node_id: node2, source: {url: "https://github.com/example/tools.git"}, remote_access: {openvscode_server: {}, ssh: {}}}
+`)
+
+		for range 2 {
+			root, err := Parse(data, WithFormat(FormatYAML))
+			require.NoError(t, err)
+
+			web, docs, tools := root.Devcontainers[0], root.Devcontainers[2], root.Devcontainers[3]
+			assert.Equal(t, 8000, *web.RemoteAccess.OpenVsCodeServer.Port)
+			assert.Equal(t, 2223, *web.RemoteAccess.Ssh.Port)
+			assert.Equal(t, 8002, *docs.RemoteAccess.OpenVsCodeServer.Port)
+			assert.Equal(t, 2224, *docs.RemoteAccess.Ssh.Port)
+			assert.Nil(t, tools.RemoteAccess.OpenVsCodeServer.Port)
+			assert.Equal(t, 2222, *tools.RemoteAccess.Ssh.Port)
+		}
+	})
+
+	t.Run("ports are unique on a node and omitted with DNS", func(t *testing.T) {
+		data := []byte(portsBase + `  - {id: web, node_id: node1, source: {url: "https://github.com/example/web.git"}, remote_access: {openvscode_server: {port: 8080}}}
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}, remote_access: {ssh: {port: 8080}}}
+  - {id: docs, node_id: node2, source: {url: "https://github.com/example/docs.git"}, remote_access: {openvscode_server: {port: 8080}}}
+`)
+
+		errs, err := Validate(data, WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		expected := ValidationErrors{
+			{Code: RulePortConflict, Pointer: "/devcontainers/1/remote_access/ssh/port", Line: 9, Column: 106, Severity: SeverityError},
+			{Code: RulePortWithDNS, Pointer: "/devcontainers/2/remote_access/openvscode_server/port", Line: 10, Column: 122, Severity: SeverityError},
+		}
+		require.Len(t, errs, len(expected))
+		for i := range errs {
+			assert.NotEmpty(t, errs[i].Message)
+			errs[i].Message = ""
+			assert.Equal(t, expected[i], errs[i])
+		}
+	})
+}
@@ -1,10 +1,36 @@
 package schema
 
-// Postprocess applies defaults and fallbacks after validation.
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Postprocess applies defaults and fallbacks before the semantic validation. Parse calls it
+// unless WithRaw is given, so it only needs to be called on models built by hand.
+// The defaults block is merged into every node and devcontainer and removed, devcontainers without
+// remote access get an OpenVSCode Server, SSH access without a public key uses the key of the node,
+// replicated devcontainers are expanded by ExpandReplicas and ports left unset are assigned the first
+// ports not used on the node, from 8000 for OpenVSCode Server and from 2222 for SSH.
+// It tolerates invalid models, which are reported by the validation afterwards.
 func Postprocess(root *DenvclustrRoot) {
+	postprocess(root)
+}
+
+// postprocess applies the defaults and fallbacks like Postprocess and returns, for each devcontainer
+// of the result, the definition it comes from.
+func postprocess(root *DenvclustrRoot) []replicaOrigin {
+	applyDefaults(root)
+
+	var origins []replicaOrigin
+	root.Devcontainers, origins = expandReplicas(root.Devcontainers)
+
 	nodes := collectNodeMap(root)
 
 	for _, devcontainer := range root.Devcontainers {
+		if devcontainer == nil {
+			continue
+		}
+
 		// ensure at least one remote‑access mechanism exists
 		if devcontainer.RemoteAccess == nil || (devcontainer.RemoteAccess.OpenVsCodeServer == nil && devcontainer.RemoteAccess.Ssh == nil) {
 			devcontainer.RemoteAccess = &DevcontainerRemoteAccess{OpenVsCodeServer: &DevcontainerOpenVSCodeServer{}}
@@ -12,7 +38,99 @@ func Postprocess(root *DenvclustrRoot) {
 
 		// fallback for SSH public key
 		if devcontainer.RemoteAccess.Ssh != nil && devcontainer.RemoteAccess.Ssh.PublicSshKey == "" {
-			devcontainer.RemoteAccess.Ssh.PublicSshKey = nodes[string(devcontainer.NodeId)].RemoteAccess.PublicSSHKey
+			if node, ok := nodes[string(devcontainer.NodeId)]; ok {
+				devcontainer.RemoteAccess.Ssh.PublicSshKey = node.RemoteAccess.PublicSSHKey
+			}
+		}
+	}
+
+	assignPorts(root)
+	return origins
+}
+
+// applyDefaults deep-merges the defaults block into every node and devcontainer, then removes it.
+// Values set on an entry win over the defaults.
+func applyDefaults(root *DenvclustrRoot) {
+	if root.Defaults == nil {
+		return
+	}
+	defer func() { root.Defaults = nil }()
+
+	if root.Defaults.Node != nil {
+		for _, node := range root.Nodes {
+			if node != nil {
+				mergeDefaults(node, root.Defaults.Node)
+			}
+		}
+	}
+	if root.Defaults.Devcontainer != nil {
+		for _, devcontainer := range root.Devcontainers {
+			if devcontainer != nil {
+				mergeDefaults(devcontainer, devcontainerDefaults(root.Defaults.Devcontainer, devcontainer))
+			}
+		}
+	}
+}
+
+// devcontainerDefaults returns the defaults applying to a devcontainer. A devcontainer running an image
+// gets no source, and a devcontainer setting a branch, tag or commit does not get the default one,
+// so a devcontainer pinned to a tag does not also get the default branch.
+func devcontainerDefaults(defaults *DevcontainerDefaults, devcontainer *Devcontainer) *DevcontainerDefaults {
+	if defaults.Source == nil {
+		return defaults
+	}
+	if devcontainer.Image != nil {
+		result := *defaults
+		result.Source = nil
+		return &result
+	}
+	if kind, _ := devcontainer.Source.Revision(); kind == "" {
+		return defaults
+	}
+	source := *defaults.Source
+	source.Branch, source.Tag, source.Commit = "", "", ""
+	result := *defaults
+	result.Source = &source
+	return &result
+}
+
+// mergeDefaults sets the fields of entry that are not set to a copy of the field of defaults with the same name.
+func mergeDefaults[T any](entry *T, defaults any) {
+	// Each entry gets its own copy, so changing an entry does not change the others.
+	// The defaults are decoded as an entry, whose fields are then merged one by one.
+	var clone T
+	data, err := json.Marshal(defaults)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return
+	}
+	mergeValue(reflect.ValueOf(entry).Elem(), reflect.ValueOf(&clone).Elem())
+}
+
+// mergeValue merges structs field by field. Any other value of dst is replaced by src when it is the zero value.
+// Pointers to other values are set, so an explicit false or 0 of an entry wins.
+func mergeValue(dst, src reflect.Value) {
+	switch dst.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(src)
+			return
+		}
+		if dst.Elem().Kind() == reflect.Struct {
+			mergeValue(dst.Elem(), src.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < dst.NumField(); i++ {
+			mergeValue(dst.Field(i), src.Field(i))
+		}
+	default:
+		if dst.IsZero() {
+			dst.Set(src)
 		}
 	}
 }
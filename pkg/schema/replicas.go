@@ -0,0 +1,135 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// replicaNamePattern matches the names of a for_each list, which are appended to the id of the devcontainer.
+var replicaNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+
+// replicaOrigin describes the definition a devcontainer of the expanded devcontainers array comes from.
+type replicaOrigin struct {
+	// index is the index of the definition in the devcontainers array.
+	index int
+	// id is the id of the definition.
+	id TrimmedString
+	// replica is the 1-based position of a replica among the instances of its definition,
+	// and 0 for a devcontainer that is not replicated.
+	replica int
+}
+
+// ExpandReplicas returns the devcontainers with every definition setting replicas or for_each replaced
+// by its instances. An instance is a copy of the definition whose id is suffixed with its number or name,
+// e.g. "api-dev-01" or "api-dev-alice", and whose ports are incremented by its position, so the instances
+// of a definition do not share ports on their node. Other devcontainers are returned as is.
+func ExpandReplicas(devcontainers []*Devcontainer) []*Devcontainer {
+	expanded, _ := expandReplicas(devcontainers)
+	return expanded
+}
+
+// expandReplicas expands the devcontainers like ExpandReplicas and also returns, for each
+// devcontainer of the result, the definition it comes from.
+func expandReplicas(devcontainers []*Devcontainer) ([]*Devcontainer, []replicaOrigin) {
+	var expanded []*Devcontainer
+	var origins []replicaOrigin
+	for i, devcontainer := range devcontainers {
+		suffixes := replicaSuffixes(devcontainer)
+		if suffixes == nil {
+			expanded = append(expanded, devcontainer)
+			origin := replicaOrigin{index: i}
+			if devcontainer != nil {
+				origin.id = devcontainer.Id
+			}
+			origins = append(origins, origin)
+			continue
+		}
+
+		for position, suffix := range suffixes {
+			replica := cloneDevcontainer(devcontainer)
+			replica.Id = TrimmedString(fmt.Sprintf("%s-%s", devcontainer.Id, suffix))
+			replica.Replicas = nil
+			replica.ForEach = nil
+			if replica.RemoteAccess != nil {
+				if replica.RemoteAccess.OpenVsCodeServer != nil {
+					replica.RemoteAccess.OpenVsCodeServer.Port = offsetPort(replica.RemoteAccess.OpenVsCodeServer.Port, position)
+				}
+				if replica.RemoteAccess.Ssh != nil {
+					replica.RemoteAccess.Ssh.Port = offsetPort(replica.RemoteAccess.Ssh.Port, position)
+				}
+			}
+			expanded = append(expanded, replica)
+			origins = append(origins, replicaOrigin{index: i, id: devcontainer.Id, replica: position + 1})
+		}
+	}
+	return expanded, origins
+}
+
+// replicaSuffixes returns the suffixes of the ids of the instances of a devcontainer, or nil
+// when it is not replicated. Numbers are zero-padded to at least two digits. A devcontainer
+// setting both replicas and for_each is not expanded, so the conflict is reported by the validation.
+func replicaSuffixes(devcontainer *Devcontainer) []string {
+	if devcontainer == nil || (devcontainer.Replicas != nil && len(devcontainer.ForEach) > 0) {
+		return nil
+	}
+	if len(devcontainer.ForEach) > 0 {
+		suffixes := make([]string, len(devcontainer.ForEach))
+		for i, name := range devcontainer.ForEach {
+			suffixes[i] = string(name)
+		}
+		return suffixes
+	}
+	if devcontainer.Replicas == nil || *devcontainer.Replicas < 1 {
+		return nil
+	}
+
+	count := *devcontainer.Replicas
+	width := max(2, len(strconv.Itoa(count)))
+	suffixes := make([]string, count)
+	for i := range suffixes {
+		suffixes[i] = fmt.Sprintf("%0*d", width, i+1)
+	}
+	return suffixes
+}
+
+func cloneDevcontainer(devcontainer *Devcontainer) *Devcontainer {
+	var clone Devcontainer
+	data, err := json.Marshal(devcontainer)
+	if err != nil {
+		return devcontainer
+	}
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return devcontainer
+	}
+	return &clone
+}
+
+func offsetPort(port *int, offset int) *int {
+	if port == nil {
+		return nil
+	}
+	result := *port + offset
+	return &result
+}
+
+// remapReplicaPointer rewrites a pointer into the expanded devcontainers array
+// into the pointer of the definition the devcontainer comes from.
+func remapReplicaPointer(p string, origins []replicaOrigin) string {
+	tokens := splitPointer(p)
+	if len(tokens) < 2 || tokens[0] != "devcontainers" {
+		return p
+	}
+	index, err := strconv.Atoi(tokens[1])
+	if err != nil || index < 0 || index >= len(origins) {
+		return p
+	}
+
+	result := make([]any, len(tokens))
+	for i, token := range tokens {
+		result[i] = token
+	}
+	result[1] = origins[index].index
+	return pointer(result...)
+}
@@ -0,0 +1,83 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReplicas(t *testing.T) {
+	data, err := testDataFS.ReadFile("testdata/replicas.yaml")
+	require.NoError(t, err, "Failed to read test file")
+
+	t.Run("definitions are expanded", func(t *testing.T) {
+		root, err := Parse(data, WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		var ids []TrimmedString
+		for _, devcontainer := range root.Devcontainers {
+			ids = append(ids, devcontainer.Id)
+			assert.Nil(t, devcontainer.Replicas)
+			assert.Nil(t, devcontainer.ForEach)
+		}
+		assert.Equal(t, []TrimmedString{"api-dev-01", "api-dev-02", "api-dev-03", "web-dev-alice", "web-dev-bob"}, ids)
+
+		for i, devcontainer := range root.Devcontainers[:3] {
+			assert.Equal(t, 8000+i, *devcontainer.RemoteAccess.OpenVsCodeServer.Port)
+			assert.Equal(t, 2200+i, *devcontainer.RemoteAccess.Ssh.Port)
+			assert.Equal(t, TrimmedString("~/.ssh/id_rsa.pub"), devcontainer.RemoteAccess.Ssh.PublicSshKey)
+		}
+	})
+
+	t.Run("raw model keeps the definitions", func(t *testing.T) {
+		root, err := Parse(data, WithFormat(FormatYAML), WithRaw())
+		require.NoError(t, err)
+
+		require.Len(t, root.Devcontainers, 2)
+		assert.Equal(t, 3, *root.Devcontainers[0].Replicas)
+	})
+
+	t.Run("errors of replicas are reported on their definition", func(t *testing.T) {
+		invalid := append(append([]byte{}, data...), []byte(`  - id: api-dev-02
+    node_id: node1
+    source:
+      url: https://github.com/example/api.git
+  - id: docs-dev
+    node_id: node1
+    replicas: 2
+    for_each: [alice]
+    source:
+      url: git@github.com:example/docs.git
+`)...)
+
+		errs, err := Validate(invalid, WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		expected := ValidationErrors{
+			{Code: RuleDuplicateId, Pointer: "/devcontainers/2/id", Line: 30, Column: 5, Severity: SeverityError},
+			{Code: RuleConflictingFields, Pointer: "/devcontainers/3/for_each", Line: 37, Column: 5, Severity: SeverityError},
+			{Code: RuleSSHKeyRequired, Pointer: "/devcontainers/3/source/ssh_key", Line: 38, Column: 5, Severity: SeverityError},
+		}
+		require.Len(t, errs, len(expected))
+		for i := range errs {
+			assert.NotEmpty(t, errs[i].Message)
+			errs[i].Message = ""
+			assert.Equal(t, expected[i], errs[i])
+		}
+	})
+
+	t.Run("ports of replicas stay in range", func(t *testing.T) {
+		errs, err := Validate([]byte(`{
+  "name": "cluster",
+  "infrastructure": [{"id": "aws", "kind": "vm", "provider": "aws", "region": "eu-central-1"}],
+  "nodes": [{"id": "node1", "infrastructure_id": "aws", "properties": {"instance_type": "t3.micro"}, "remote_access": {"public_ssh_key": "~/.ssh/id.pub"}}],
+  "devcontainers": [{"id": "web", "node_id": "node1", "replicas": 3, "source": {"url": "https://github.com/example/web.git"}, "remote_access": {"ssh": {"port": 65534}}}]
+}`))
+		require.NoError(t, err)
+		require.Len(t, errs, 1)
+		assert.Equal(t, RulePortOutOfRange, errs[0].Code)
+		assert.Equal(t, "/devcontainers/0/remote_access/ssh/port", errs[0].Pointer)
+		assert.Equal(t, `replica 3 of "web" would use ssh port 65536, out of range (1024-65535)`, errs[0].Message)
+	})
+}
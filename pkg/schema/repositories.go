@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DevcontainerRepository is a repository cloned into the workspace of a devcontainer, next to its source.
+type DevcontainerRepository struct {
+	URL    TrimmedString             `json:"url" jsonschema:"required,minLength=1" jsonschema_description:"Git repository URL, in the same forms as 'source.url'. For SSH URLs, an SSH key must be provided."`
+	Branch TrimmedString             `json:"branch,omitempty" jsonschema_description:"Git branch to checkout. If neither a branch nor a tag is specified, the repository's default branch will be used. Cannot be used with 'tag'."`
+	Tag    TrimmedString             `json:"tag,omitempty" jsonschema:"minLength=1" jsonschema_description:"Git tag to checkout. Cannot be used with 'branch'."`
+	Path   TrimmedString             `json:"path" jsonschema:"required,minLength=1" jsonschema_description:"Directory the repository is cloned into, relative to the workspace of the devcontainer, e.g. 'services/billing'. Must be unique within the devcontainer."`
+	SshKey *DevcontainerSourceSSHKey `json:"ssh_key,omitempty" jsonschema_description:"SSH key configuration for cloning from private Git repositories. Required for SSH URLs, must be omitted for HTTPS URLs."`
+}
+
+// validateAdditionalRepositories checks the additional repositories of the devcontainer at index i
+// with the rules of its source, and that each one is cloned into its own directory of the workspace.
+func validateAdditionalRepositories(devcontainer *Devcontainer, i int, c *collector) {
+	paths := make(map[string]int)
+	for j, repository := range devcontainer.AdditionalRepositories {
+		if repository == nil {
+			continue
+		}
+		owner := fmt.Sprintf("%s: additional_repositories[%d]", c.devcontainer(i, devcontainer), j)
+		p := pointer("devcontainers", i, "additional_repositories", j)
+
+		if target := string(repository.Path); target != "" {
+			cleaned := path.Clean(target)
+			switch {
+			case path.IsAbs(target) || cleaned == ".." || strings.HasPrefix(cleaned, "../") || cleaned == ".":
+				c.add(RuleInvalidPath, p+"/path", "%s: path %q must be a directory inside the workspace", owner, target)
+			default:
+				if first, exists := paths[cleaned]; exists {
+					c.add(RuleInvalidPath, p+"/path", "%s: path %q is already used by additional_repositories[%d]", owner, target, first)
+				} else {
+					paths[cleaned] = j
+				}
+			}
+		}
+
+		validateRevision(c, p, owner, []revisionField{{"branch", repository.Branch}, {"tag", repository.Tag}})
+		if repository.URL != "" {
+			validateRepositoryAccess(c, p, owner, "url", repository.URL, repository.SshKey)
+		}
+	}
+}
+
+// revisionField is one of the fields selecting the revision a repository is checked out at.
+type revisionField struct {
+	name  string
+	value TrimmedString
+}
+
+// validateRevision checks that at most one of the revision fields of the repository at p is set.
+func validateRevision(c *collector, p, owner string, fields []revisionField) {
+	var names, set []string
+	for _, field := range fields {
+		names = append(names, field.name)
+		if field.value != "" {
+			set = append(set, field.name)
+		}
+	}
+	if len(set) > 1 {
+		c.add(RuleConflictingFields, p+"/"+set[1], "%s: %s cannot be used together, set only one of %s",
+			owner, strings.Join(set, " and "), strings.Join(names[:len(names)-1], ", ")+" and "+names[len(names)-1],
+		)
+	}
+}
+
+// validateRepositoryAccess checks the URL of the repository at p, and that an SSH key is set exactly for SSH URLs.
+// The field names the URL in messages, e.g. "source.url".
+func validateRepositoryAccess(c *collector, p, owner, field string, url TrimmedString, sshKey *DevcontainerSourceSSHKey) {
+	gitURL, err := ParseGitURL(string(url))
+	if err != nil {
+		c.add(RuleInvalidGitURL, p+"/url", "%s: invalid %s: %v", owner, field, err)
+		return
+	}
+
+	if gitURL.Password != "" || (gitURL.Transport == GitTransportHTTPS && gitURL.User != "") {
+		c.add(RuleCredentialsInURL, p+"/url", "%s: %s must not contain credentials, use an SSH URL with ssh_key for private repositories", owner, field)
+	}
+
+	isSSH := gitURL.IsSSH()
+
+	if isSSH && sshKey == nil {
+		c.add(RuleSSHKeyRequired, p+"/ssh_key", "%s: ssh_key must be provided for SSH-based URLs", owner)
+	}
+
+	if !isSSH && sshKey != nil {
+		c.add(RuleSSHKeyForbidden, p+"/ssh_key", "%s: ssh_key must not be used with non-SSH URLs", owner)
+	}
+}
@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAdditionalRepositories(t *testing.T) {
+	const base = `name: workspace-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+devcontainers:
+  - id: platform
+    node_id: node1
+    source: {url: "https://github.com/example/platform.git"}
+    additional_repositories:
+      - {url: "https://github.com/example/billing.git", path: services/billing, tag: v2.0.1}
+      - %s
+`
+
+	t.Run("valid repositories", func(t *testing.T) {
+		root, err := Parse([]byte(fmt.Sprintf(base,
+			`{url: "git@github.com:example/identity.git", path: services/identity, branch: main, ssh_key: {source: ssm_parameter_store, reference: /keys/identity}}`,
+		)), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		require.Len(t, root.Devcontainers[0].AdditionalRepositories, 2)
+		assert.Equal(t, &DevcontainerRepository{
+			URL:    "git@github.com:example/identity.git",
+			Branch: "main",
+			Path:   "services/identity",
+			SshKey: &DevcontainerSourceSSHKey{Reference: "/keys/identity", Source: SshKeySourceSsmParameterStore},
+		}, root.Devcontainers[0].AdditionalRepositories[1])
+	})
+
+	tests := []struct {
+		name       string
+		repository string
+		code       string
+		pointer    string
+		message    string
+	}{
+		{"SSH URL without SSH key", `{url: "ssh://git@github.com/example/identity.git", path: services/identity}`, RuleSSHKeyRequired,
+			"/devcontainers/0/additional_repositories/1/ssh_key", `devcontainer "platform": additional_repositories[1]: ssh_key must be provided for SSH-based URLs`},
+		{"HTTPS URL with SSH key", `{url: "https://github.com/example/identity.git", path: services/identity, ssh_key: {source: secrets_manager, reference: identity}}`, RuleSSHKeyForbidden,
+			"/devcontainers/0/additional_repositories/1/ssh_key", `devcontainer "platform": additional_repositories[1]: ssh_key must not be used with non-SSH URLs`},
+		{"credentials in URL", `{url: "https://token@github.com/example/identity.git", path: services/identity}`, RuleCredentialsInURL,
+			"/devcontainers/0/additional_repositories/1/url", `devcontainer "platform": additional_repositories[1]: url must not contain credentials, use an SSH URL with ssh_key for private repositories`},
+		{"branch and tag", `{url: "https://github.com/example/identity.git", path: services/identity, branch: main, tag: v1.0.0}`, RuleConflictingFields,
+			"/devcontainers/0/additional_repositories/1/tag", `devcontainer "platform": additional_repositories[1]: branch and tag cannot be used together, set only one of branch and tag`},
+		{"duplicate path", `{url: "https://github.com/example/identity.git", path: ./services/billing/}`, RuleInvalidPath,
+			"/devcontainers/0/additional_repositories/1/path", `devcontainer "platform": additional_repositories[1]: path "./services/billing/" is already used by additional_repositories[0]`},
+		{"path outside the workspace", `{url: "https://github.com/example/identity.git", path: ../identity}`, RuleInvalidPath,
+			"/devcontainers/0/additional_repositories/1/path", `devcontainer "platform": additional_repositories[1]: path "../identity" must be a directory inside the workspace`},
+		{"missing path", `{url: "https://github.com/example/identity.git"}`, RuleSchemaPrefix + "required",
+			"/devcontainers/0/additional_repositories/1", "missing property 'path'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate([]byte(fmt.Sprintf(base, tt.repository)), WithFormat(FormatYAML))
+			require.NoError(t, err)
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.code, errs[0].Code)
+			assert.Equal(t, tt.pointer, errs[0].Pointer)
+			assert.Equal(t, tt.message, errs[0].Message)
+		})
+	}
+}
@@ -2,8 +2,38 @@ package schema
 
 // DenvclustrRoot describes the top-level JSON structure:
 type DenvclustrRoot struct {
-	Name           TrimmedString     `json:"name" jsonschema:"required,minLength=1" jsonschema_description:"Unique identifier for the cluster."`
-	Infrastructure []*Infrastructure `json:"infrastructure" jsonschema:"required,minItems=1" jsonschema_description:"List of infrastructure backends where nodes may be deployed."`
-	Nodes          []*Node           `json:"nodes" jsonschema:"required,minItems=1" jsonschema_description:"List of nodes where devcontainers will be deployed."`
-	Devcontainers  []*Devcontainer   `json:"devcontainers" jsonschema:"required,minItems=1" jsonschema_description:"List of devcontainers that will be deployed on nodes."`
+	Version        TrimmedString            `json:"version,omitempty" jsonschema:"enum=1" jsonschema_description:"Version of the denvclustr file format. Files without a version are read as version 1. Older files can be upgraded with 'denvclustr migrate'."`
+	Variables      map[string]TrimmedString `json:"variables,omitempty" jsonschema_description:"Variables referenced as '${var.NAME}' in string values, mapped to their default value. Values can be set with '--var' and '--var-file'. A null default makes the variable required. Environment variables are referenced as '${env.NAME}'."`
+	Include        []TrimmedString          `json:"include,omitempty" jsonschema_description:"Paths or glob patterns of files to include, relative to this file. Included files may only define 'infrastructure', 'nodes' and 'devcontainers', which are appended to the arrays of this file. They may include other files."`
+	Defaults       *Defaults                `json:"defaults,omitempty" jsonschema_description:"Values applied to every node and devcontainer. Objects are merged recursively, and values set on an entry win."`
+	Tags           map[string]TrimmedString `json:"tags,omitempty" jsonschema_description:"Tags of every resource deployed for the cluster, e.g. for cost allocation. Merged with the tags of the levels above, whose values are overridden. Keys are at most 128 characters long and cannot start with 'aws:', values at most 256 characters."`
+	ExpiresAt      TrimmedString            `json:"expires_at,omitempty" jsonschema:"minLength=1" jsonschema_description:"Time the cluster expires, in RFC 3339 format, e.g. '2024-06-30T18:00:00Z'. Expired clusters are destroyed by 'denvclustr reap'. Cannot be used with 'ttl'."`
+	TTL            TrimmedString            `json:"ttl,omitempty" jsonschema:"minLength=1" jsonschema_description:"Time to live of the cluster from its deployment, e.g. '8h' or '7d'. Expired clusters are destroyed by 'denvclustr reap'. Cannot be used with 'expires_at'."`
+	Name           TrimmedString            `json:"name" jsonschema:"required,minLength=1" jsonschema_description:"Unique identifier for the cluster."`
+	Infrastructure []*Infrastructure        `json:"infrastructure" jsonschema:"required,minItems=1" jsonschema_description:"List of infrastructure backends where nodes may be deployed."`
+	Nodes          []*Node                  `json:"nodes" jsonschema:"required,minItems=1" jsonschema_description:"List of nodes where devcontainers will be deployed."`
+	Devcontainers  []*Devcontainer          `json:"devcontainers" jsonschema:"required,minItems=1" jsonschema_description:"List of devcontainers that will be deployed on nodes."`
+}
+
+// Defaults describes the values merged into every entry of the nodes and devcontainers arrays.
+type Defaults struct {
+	Node         *NodeDefaults         `json:"node,omitempty" jsonschema_description:"Default values of every node, e.g. its properties and remote access."`
+	Devcontainer *DevcontainerDefaults `json:"devcontainer,omitempty" jsonschema_description:"Default values of every devcontainer, e.g. its source branch, devcontainer path and remote access."`
+}
+
+// NodeDefaults holds the values of a node that can be set for every node. Any of them may be omitted.
+type NodeDefaults struct {
+	InfrastructureId TrimmedString     `json:"infrastructure_id,omitempty" jsonschema:"minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Reference to an entry in the 'infrastructure' array."`
+	Properties       *NodeProperties   `json:"properties,omitempty" jsonschema_description:"General technical configuration of the node."`
+	RemoteAccess     *NodeRemoteAccess `json:"remote_access,omitempty" jsonschema_description:"Access configuration for the node."`
+	DNS              *NodeDNS          `json:"dns,omitempty" jsonschema_description:"DNS configuration for this node."`
+	Network          *NodeNetwork      `json:"network,omitempty" jsonschema_description:"Network configuration of every node, e.g. its VPC and the CIDR blocks allowed to connect to it."`
+	Schedule         *NodeSchedule     `json:"schedule,omitempty" jsonschema_description:"When every node is started and stopped, e.g. during office hours."`
+}
+
+// DevcontainerDefaults holds the values of a devcontainer that can be set for every devcontainer. Any of them may be omitted.
+type DevcontainerDefaults struct {
+	NodeId       TrimmedString             `json:"node_id,omitempty" jsonschema:"minLength=1,pattern=^[_a-zA-Z][a-zA-Z0-9-]*[a-zA-Z0-9]$" jsonschema_description:"Identifier of the node that will host the devcontainers."`
+	Source       *DevcontainerSource       `json:"source,omitempty" jsonschema_description:"Reference to the source location containing the devcontainer definition and related files."`
+	RemoteAccess *DevcontainerRemoteAccess `json:"remote_access,omitempty" jsonschema_description:"Configuration for accessing the devcontainers remotely via SSH or a web-based IDE."`
 }
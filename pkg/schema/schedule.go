@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"fmt"
+	"time"
+
+	// Time zones are checked on systems without a time zone database too
+	_ "time/tzdata"
+)
+
+type NodeSchedule struct {
+	Start    TrimmedString `json:"start" jsonschema:"required,minLength=1" jsonschema_description:"Cron expression of when the node is started, with the fields minute, hour, day of month, month and day of week, e.g. '0 8 * * MON-FRI'."`
+	Stop     TrimmedString `json:"stop" jsonschema:"required,minLength=1" jsonschema_description:"Cron expression of when the node is stopped, e.g. '0 19 * * MON-FRI'."`
+	Timezone TrimmedString `json:"timezone,omitempty" jsonschema:"minLength=1" jsonschema_description:"IANA time zone the cron expressions are evaluated in, e.g. 'Europe/Berlin'. If not specified, 'UTC' is used."`
+}
+
+// Location returns the time zone of the schedule, UTC unless set.
+func (s *NodeSchedule) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(string(s.Timezone))
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q, expected an IANA time zone such as Europe/Berlin", s.Timezone)
+	}
+	return loc, nil
+}
+
+// NextStart returns the first time after t the node is started, in the time zone of the schedule.
+func (s *NodeSchedule) NextStart(t time.Time) (time.Time, error) {
+	return s.next(s.Start, t)
+}
+
+// NextStop returns the first time after t the node is stopped, in the time zone of the schedule.
+func (s *NodeSchedule) NextStop(t time.Time) (time.Time, error) {
+	return s.next(s.Stop, t)
+}
+
+func (s *NodeSchedule) next(expression TrimmedString, t time.Time) (time.Time, error) {
+	loc, err := s.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	cron, err := ParseCron(string(expression))
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := cron.Next(t.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("%q never matches", expression)
+	}
+	return next, nil
+}
+
+// validateSchedules checks the cron expressions and time zones of the node schedules.
+func validateSchedules(root *DenvclustrRoot, c *collector) {
+	// Any time works to check that the expressions match at least once
+	reference := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, node := range root.Nodes {
+		schedule := node.Schedule
+		if schedule == nil {
+			continue
+		}
+		p := pointer("nodes", i, "schedule")
+
+		if _, err := schedule.Location(); err != nil {
+			c.add(RuleInvalidSchedule, p+pointer("timezone"), "node %q: %v", node.Id, err)
+		}
+		for _, field := range []struct {
+			name  string
+			value TrimmedString
+		}{
+			{"start", schedule.Start}, {"stop", schedule.Stop},
+		} {
+			if field.value == "" {
+				continue
+			}
+			cron, err := ParseCron(string(field.value))
+			if err != nil {
+				c.add(RuleInvalidSchedule, p+pointer(field.name), "node %q: %s: %v", node.Id, field.name, err)
+			} else if cron.Next(reference).IsZero() {
+				c.add(RuleInvalidSchedule, p+pointer(field.name), "node %q: %s: %q never matches", node.Id, field.name, field.value)
+			}
+		}
+		if schedule.Start != "" && schedule.Start == schedule.Stop {
+			c.add(RuleInvalidSchedule, p+pointer("stop"), "node %q: stop is the same as start, the node would be stopped as soon as it is started", node.Id)
+		}
+	}
+}
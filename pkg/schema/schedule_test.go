@@ -0,0 +1,121 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	// Friday, March 29, 2024, the last Friday before the change to summer time in Berlin
+	friday := time.Date(2024, time.March, 29, 12, 0, 0, 0, berlin)
+
+	tests := []struct {
+		expression  string
+		next        time.Time
+		eventBridge string
+	}{
+		{"30 19 * * MON-FRI", time.Date(2024, time.March, 29, 19, 30, 0, 0, berlin), "cron(30 19 ? * MON-FRI *)"},
+		{"0 8 * * 1-5", time.Date(2024, time.April, 1, 8, 0, 0, 0, berlin), "cron(0 8 ? * MON-FRI *)"},
+		{"0 2 * * 0", time.Date(2024, time.March, 31, 3, 0, 0, 0, berlin), "cron(0 2 ? * SUN *)"},
+		{"0 10 * * 6,7", time.Date(2024, time.March, 30, 10, 0, 0, 0, berlin), "cron(0 10 ? * SUN,SAT *)"},
+		{"*/20 9-17/4 * * *", time.Date(2024, time.March, 29, 13, 0, 0, 0, berlin), "cron(*/20 9-17/4 * * ? *)"},
+		{"0 0 1 jan,jul *", time.Date(2024, time.July, 1, 0, 0, 0, 0, berlin), "cron(0 0 1 JAN,JUL ? *)"},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, berlin), "cron(0 0 29 2 ? *)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			cron, err := ParseCron(tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.next, cron.Next(friday))
+			assert.Equal(t, tt.eventBridge, cron.EventBridge())
+		})
+	}
+
+	t.Run("never matches", func(t *testing.T) {
+		cron, err := ParseCron("0 0 30 2 *")
+		require.NoError(t, err)
+		assert.True(t, cron.Next(friday).IsZero())
+	})
+
+	for _, expression := range []string{"0 8 * *", "60 8 * * *", "0 8 * * MON-XYZ", "0 8 * * */2", "0 8 1 * MON", "0 18-8 * * *"} {
+		t.Run(expression, func(t *testing.T) {
+			_, err := ParseCron(expression)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	const base = `name: office-hours-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - id: node1
+    infrastructure_id: aws
+    properties: %s
+    remote_access: {public_ssh_key: ~/.ssh/id.pub}
+    schedule: %s
+devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}}
+`
+
+	t.Run("next stop", func(t *testing.T) {
+		root, err := Parse([]byte(fmt.Sprintf(base, `{instance_type: t3.large}`,
+			`{start: "0 8 * * MON-FRI", stop: "0 19 * * MON-FRI", timezone: America/New_York}`,
+		)), WithFormat(FormatYAML))
+		require.NoError(t, err)
+
+		next, err := root.Nodes[0].Schedule.NextStop(time.Date(2024, time.March, 29, 22, 30, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, "2024-03-29 19:00:00 -0400 EDT", next.String())
+
+		next, err = root.Nodes[0].Schedule.NextStart(next)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-04-01 08:00:00 -0400 EDT", next.String())
+	})
+
+	tests := []struct {
+		name       string
+		properties string
+		schedule   string
+		code       string
+		pointer    string
+		message    string
+	}{
+		{"unknown time zone", `{instance_type: t3.large}`, `{start: "0 8 * * *", stop: "0 19 * * *", timezone: Mars/Olympus}`, RuleInvalidSchedule,
+			"/nodes/0/schedule/timezone", `node "node1": unknown time zone "Mars/Olympus", expected an IANA time zone such as Europe/Berlin`},
+		{"invalid cron", `{instance_type: t3.large}`, `{start: "0 25 * * *", stop: "0 19 * * *"}`, RuleInvalidSchedule,
+			"/nodes/0/schedule/start", `node "node1": start: "0 25 * * *": invalid hour "25", expected 0-23`},
+		{"never matches", `{instance_type: t3.large}`, `{start: "0 8 * * *", stop: "0 19 31 4 *"}`, RuleInvalidSchedule,
+			"/nodes/0/schedule/stop", `node "node1": stop: "0 19 31 4 *" never matches`},
+		{"same start and stop", `{instance_type: t3.large}`, `{start: "0 8 * * *", stop: "0 8 * * *"}`, RuleInvalidSchedule,
+			"/nodes/0/schedule/stop", `node "node1": stop is the same as start, the node would be stopped as soon as it is started`},
+		{"spot terminated on interruption", `{instance_type: t3.large, market: spot, interruption_behavior: terminate}`, `{start: "0 8 * * *", stop: "0 19 * * *"}`, RuleConflictingFields,
+			"/nodes/0/properties/interruption_behavior", `node "node1": the workspaces of the devcontainers would be deleted when the node is interrupted, use stop to keep them`},
+		{"missing stop", `{instance_type: t3.large}`, `{start: "0 8 * * *"}`, RuleSchemaPrefix + "required",
+			"/nodes/0/schedule", "missing property 'stop'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate([]byte(fmt.Sprintf(base, tt.properties, tt.schedule)), WithFormat(FormatYAML))
+			require.NoError(t, err)
+
+			var found ValidationErrors
+			for _, e := range errs {
+				if e.Severity == SeverityError {
+					found = append(found, e)
+				}
+			}
+			require.Len(t, found, 1)
+			assert.Equal(t, tt.code, found[0].Code)
+			assert.Equal(t, tt.pointer, found[0].Pointer)
+			assert.Equal(t, tt.message, found[0].Message)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v3"
+)
+
+// PublicKey describes a public SSH key file.
+type PublicKey struct {
+	// Path is the path of the file, as written in the denvclustr file or relative to its directory.
+	Path string
+	// Type is the key algorithm, e.g. "ssh-ed25519".
+	Type string
+	// Fingerprint is the SHA256 fingerprint of the key, as printed by ssh-keygen -l.
+	Fingerprint string
+}
+
+// ReadPublicKey reads a public SSH key file in the OpenSSH format, e.g. "~/.ssh/id_ed25519.pub".
+// A leading "~" is expanded to the home directory, and other relative paths are relative to the current
+// directory: the paths of a parsed model are already relative to the file setting them. Private keys are rejected.
+func ReadPublicKey(path string) (*PublicKey, error) {
+	expanded, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(expanded)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("file %q not found", path)
+		}
+		return nil, fmt.Errorf("read %q: %w", path, err)
+	}
+
+	if bytes.Contains(data, []byte("PRIVATE KEY-----")) {
+		return nil, fmt.Errorf("%q is a private key, use the matching public key, e.g. %q", path, path+".pub")
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not an OpenSSH public key: %v", path, err)
+	}
+	return &PublicKey{Path: path, Type: key.Type(), Fingerprint: ssh.FingerprintSHA256(key)}, nil
+}
+
+// PublicKeyPaths returns the public SSH key files used by the nodes and devcontainers, each once, in order.
+func PublicKeyPaths(root *DenvclustrRoot) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, check := range publicKeyChecks(root, &collector{}) {
+		if !seen[check.path] {
+			seen[check.path] = true
+			paths = append(paths, check.path)
+		}
+	}
+	return paths
+}
+
+// publicKeyCheck is a public SSH key file set by a node or a devcontainer.
+type publicKeyCheck struct {
+	pointer string
+	owner   string
+	path    string
+}
+
+// publicKeyChecks returns the public SSH keys of the nodes and devcontainers, whose owners are named by
+// the collector. Devcontainers using the key of their node are left out, so each key is checked where it is set.
+func publicKeyChecks(root *DenvclustrRoot, c *collector) []publicKeyCheck {
+	var checks []publicKeyCheck
+	for i, node := range root.Nodes {
+		if node.RemoteAccess.PublicSSHKey != "" {
+			checks = append(checks, publicKeyCheck{
+				pointer("nodes", i, "remote_access", "public_ssh_key"), fmt.Sprintf("node %q", node.Id), string(node.RemoteAccess.PublicSSHKey),
+			})
+		}
+	}
+
+	nodes := collectNodeMap(root)
+	for i, devcontainer := range root.Devcontainers {
+		if devcontainer.RemoteAccess == nil || devcontainer.RemoteAccess.Ssh == nil || devcontainer.RemoteAccess.Ssh.PublicSshKey == "" {
+			continue
+		}
+		key := devcontainer.RemoteAccess.Ssh.PublicSshKey
+		if node, ok := nodes[string(devcontainer.NodeId)]; ok && node.RemoteAccess.PublicSSHKey == key {
+			continue
+		}
+		checks = append(checks, publicKeyCheck{
+			pointer("devcontainers", i, "remote_access", "ssh", "public_ssh_key"), c.devcontainer(i, devcontainer), string(key),
+		})
+	}
+	return checks
+}
+
+// validatePublicKeys checks that the public SSH key files of the nodes and devcontainers exist and are OpenSSH public keys.
+func validatePublicKeys(root *DenvclustrRoot, c *collector) {
+	for _, check := range publicKeyChecks(root, c) {
+		if _, err := ReadPublicKey(check.path); err != nil {
+			c.add(RuleInvalidSSHKey, check.pointer, "%s: invalid public SSH key: %v", check.owner, err)
+		}
+	}
+}
+
+// resolvePublicKeyPaths makes the relative public SSH key paths of a document relative to the directory of
+// the file setting them, as the paths of the include list are: baseDir for the parsed file, and the directory
+// of their name for included files and overlays. Paths starting with "~" are left as is.
+func resolvePublicKeyPaths(doc *document, baseDir string) {
+	resolve := func(node *yaml.Node, keys ...string) {
+		key, value := descendant(node, keys...)
+		if value == nil || value.Kind != yaml.ScalarNode || value.Tag != "!!str" || !isRelativeKeyPath(value.Value) {
+			return
+		}
+		// The key is located like errors are, so a value replaced by an overlay is relative to the overlay
+		dir := baseDir
+		if origin := doc.origins[key]; origin != "" {
+			dir = filepath.Dir(origin)
+		}
+		if dir != "." {
+			value.Value = filepath.Join(dir, value.Value)
+		}
+	}
+
+	if _, nodes := childNode(doc.root, "nodes"); nodes != nil && nodes.Kind == yaml.SequenceNode {
+		for _, node := range nodes.Content {
+			resolve(node, "remote_access", "public_ssh_key")
+		}
+	}
+	if _, devcontainers := childNode(doc.root, "devcontainers"); devcontainers != nil && devcontainers.Kind == yaml.SequenceNode {
+		for _, devcontainer := range devcontainers.Content {
+			resolve(devcontainer, "remote_access", "ssh", "public_ssh_key")
+		}
+	}
+	resolve(doc.root, "defaults", "node", "remote_access", "public_ssh_key")
+	resolve(doc.root, "defaults", "devcontainer", "remote_access", "ssh", "public_ssh_key")
+}
+
+// descendant returns the member of nested mapping nodes at the given keys, along with its key node.
+func descendant(node *yaml.Node, keys ...string) (*yaml.Node, *yaml.Node) {
+	var key *yaml.Node
+	for _, token := range keys {
+		if node == nil {
+			return nil, nil
+		}
+		key, node = childNode(node, token)
+	}
+	return key, node
+}
+
+// isRelativeKeyPath reports whether a public SSH key path is relative to the directory of its file.
+func isRelativeKeyPath(path string) bool {
+	return path != "" && !filepath.IsAbs(path) && !strings.HasPrefix(path, "~")
+}
+
+// expandHome replaces a leading "~" of a path with the home directory of the current user.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("expand %q: %w", path, err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
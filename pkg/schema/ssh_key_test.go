@@ -0,0 +1,151 @@
+package schema
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// writeKeyPair writes an ed25519 key pair to dir, as ssh-keygen would, and returns the public key.
+func writeKeyPair(t *testing.T, dir, name string) ssh.PublicKey {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := ssh.MarshalPrivateKey(private, "")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0600))
+
+	key, err := ssh.NewPublicKey(public)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".pub"), ssh.MarshalAuthorizedKey(key), 0644))
+	return key
+}
+
+func TestReadPublicKey(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.Mkdir(filepath.Join(home, ".ssh"), 0700))
+	key := writeKeyPair(t, filepath.Join(home, ".ssh"), "id_ed25519")
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".ssh", "notes.txt"), []byte("not a key\n"), 0644))
+
+	t.Run("home is expanded", func(t *testing.T) {
+		publicKey, err := ReadPublicKey("~/.ssh/id_ed25519.pub")
+		require.NoError(t, err)
+		assert.Equal(t, &PublicKey{Path: "~/.ssh/id_ed25519.pub", Type: "ssh-ed25519", Fingerprint: ssh.FingerprintSHA256(key)}, publicKey)
+	})
+
+	t.Run("invalid files are rejected", func(t *testing.T) {
+		_, err := ReadPublicKey("~/.ssh/id_rsa.pub")
+		assert.EqualError(t, err, `file "~/.ssh/id_rsa.pub" not found`)
+
+		_, err = ReadPublicKey("~/.ssh/id_ed25519")
+		assert.EqualError(t, err, `"~/.ssh/id_ed25519" is a private key, use the matching public key, e.g. "~/.ssh/id_ed25519.pub"`)
+
+		_, err = ReadPublicKey("~/.ssh/notes.txt")
+		assert.ErrorContains(t, err, `"~/.ssh/notes.txt" is not an OpenSSH public key`)
+	})
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyPair(t, dir, "node")
+	writeKeyPair(t, dir, "api")
+
+	data := []byte(fmt.Sprintf(`name: keys-cluster
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: %[1]s/node.pub}}
+devcontainers:
+  - {id: web, node_id: node1, source: {url: "https://github.com/example/web.git"}, remote_access: {ssh: {}}}
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}, remote_access: {ssh: {public_ssh_key: %[1]s/api}}}
+  - {id: docs, node_id: node1, source: {url: "https://github.com/example/docs.git"}, remote_access: {ssh: {public_ssh_key: %[1]s/docs.pub}}}
+`, dir))
+
+	t.Run("key files are only read when asked", func(t *testing.T) {
+		errs, err := Validate(data, WithFormat(FormatYAML))
+		require.NoError(t, err)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("invalid key files are validation errors", func(t *testing.T) {
+		errs, err := Validate(data, WithFormat(FormatYAML), WithPublicKeyCheck())
+		require.NoError(t, err)
+
+		require.Len(t, errs, 2)
+		assert.Equal(t, RuleInvalidSSHKey, errs[0].Code)
+		assert.Equal(t, "/devcontainers/1/remote_access/ssh/public_ssh_key", errs[0].Pointer)
+		assert.Contains(t, errs[0].Message, `devcontainer "api": invalid public SSH key:`)
+		assert.Contains(t, errs[0].Message, "is a private key")
+		assert.Equal(t, "/devcontainers/2/remote_access/ssh/public_ssh_key", errs[1].Pointer)
+		assert.Contains(t, errs[1].Message, "not found")
+	})
+
+	t.Run("key paths are listed once", func(t *testing.T) {
+		root, err := Parse(data, WithFormat(FormatYAML))
+		require.NoError(t, err)
+		assert.Equal(t, []string{dir + "/node.pub", dir + "/api", dir + "/docs.pub"}, PublicKeyPaths(root))
+	})
+}
+
+func TestParsePublicKeysRelativeToFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"config/keys", "config/team", "config/overlays"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0755))
+	}
+	writeKeyPair(t, filepath.Join(dir, "config", "keys"), "node")
+	writeKeyPair(t, filepath.Join(dir, "config", "team"), "api")
+	writeKeyPair(t, filepath.Join(dir, "config", "overlays"), "web")
+
+	data := []byte(`name: keys-cluster
+include:
+  - team/api.yaml
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: keys/node.pub}}
+devcontainers:
+  - {id: web, node_id: node1, source: {url: "https://github.com/example/web.git"}, remote_access: {ssh: {public_ssh_key: keys/node.pub}}}
+`)
+	fragment := `devcontainers:
+  - {id: api, node_id: node1, source: {url: "https://github.com/example/api.git"}, remote_access: {ssh: {public_ssh_key: ./api.pub}}}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config", "team", "api.yaml"), []byte(fragment), 0644))
+	overlay := []byte(`devcontainers:
+  - {id: web, remote_access: {ssh: {public_ssh_key: web.pub}}}
+`)
+
+	// The files are parsed from another directory, as 'denvclustr validate config/cluster.yaml' would
+	t.Chdir(t.TempDir())
+	options := []Option{
+		WithFormat(FormatYAML),
+		WithPath(filepath.Join(dir, "config", "cluster.yaml")),
+		WithOverlay(filepath.Join(dir, "config", "overlays", "web.yaml"), overlay, FormatYAML),
+	}
+
+	errs, err := Validate(data, append(options, WithPublicKeyCheck())...)
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+
+	root, err := Parse(data, options...)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "config", "keys", "node.pub"),
+		filepath.Join(dir, "config", "overlays", "web.pub"),
+		filepath.Join(dir, "config", "team", "api.pub"),
+	}, PublicKeyPaths(root))
+
+	// The directory of the parsed file can be given without its path
+	errs, err = Validate(data, WithFormat(FormatYAML), WithBaseDir(filepath.Join(dir, "config")), WithPublicKeyCheck())
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+}
@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of the tags of an AWS resource.
+const (
+	maxTags           = 50
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+// tagPattern matches the characters allowed in the keys and values of AWS tags.
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// MergeTags merges the tags of nested levels, e.g. of the root, an infrastructure and a node.
+// Tags of later levels win. The result is nil when no level sets a tag.
+func MergeTags(levels ...map[string]TrimmedString) map[string]TrimmedString {
+	var merged map[string]TrimmedString
+	for _, tags := range levels {
+		for key, value := range tags {
+			if merged == nil {
+				merged = make(map[string]TrimmedString)
+			}
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// validateTags checks the tags set at every level against the limits of AWS, including
+// the number of tags each resource gets once the levels are merged.
+func validateTags(root *DenvclustrRoot, c *collector) {
+	validateTagSet(c, pointer("tags"), "cluster", root.Tags, 0, len(root.Tags))
+
+	infrastructureMap := make(map[string]*Infrastructure)
+	for i, infrastructure := range root.Infrastructure {
+		if _, ok := infrastructureMap[string(infrastructure.Id)]; !ok {
+			infrastructureMap[string(infrastructure.Id)] = infrastructure
+		}
+		merged := len(MergeTags(root.Tags, infrastructure.Tags))
+		validateTagSet(c, pointer("infrastructure", i, "tags"), fmt.Sprintf("infrastructure %q", infrastructure.Id), infrastructure.Tags, len(root.Tags), merged)
+	}
+
+	nodeTags := make(map[string]map[string]TrimmedString)
+	for i, node := range root.Nodes {
+		var infrastructureTags map[string]TrimmedString
+		if infrastructure, ok := infrastructureMap[string(node.InfrastructureId)]; ok {
+			infrastructureTags = infrastructure.Tags
+		}
+		parent := len(MergeTags(root.Tags, infrastructureTags))
+		merged := MergeTags(root.Tags, infrastructureTags, node.Tags)
+		if _, ok := nodeTags[string(node.Id)]; !ok {
+			nodeTags[string(node.Id)] = merged
+		}
+		validateTagSet(c, pointer("nodes", i, "tags"), fmt.Sprintf("node %q", node.Id), node.Tags, parent, len(merged))
+	}
+
+	for i, devcontainer := range root.Devcontainers {
+		parent := nodeTags[string(devcontainer.NodeId)]
+		merged := len(MergeTags(parent, devcontainer.Tags))
+		validateTagSet(c, pointer("devcontainers", i, "tags"), c.devcontainer(i, devcontainer), devcontainer.Tags, len(parent), merged)
+	}
+}
+
+// validateTagSet checks the tags set by one owner. parent and merged are the number of tags of the
+// levels above the owner, without and with its own tags. Too many tags are reported at the first level exceeding the limit.
+func validateTagSet(c *collector, p string, owner string, tags map[string]TrimmedString, parent, merged int) {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := string(tags[key])
+		switch {
+		case key == "" || utf8.RuneCountInString(key) > maxTagKeyLength:
+			c.add(RuleInvalidTag, p+pointer(key), "%s: tag key %q must be 1 to %d characters long", owner, key, maxTagKeyLength)
+		case strings.HasPrefix(strings.ToLower(key), "aws:"):
+			c.add(RuleInvalidTag, p+pointer(key), "%s: tag key %q uses the prefix 'aws:', which is reserved by AWS", owner, key)
+		case !tagPattern.MatchString(key):
+			c.add(RuleInvalidTag, p+pointer(key), "%s: tag key %q may only contain letters, numbers, spaces and _ . : / = + - @", owner, key)
+		case utf8.RuneCountInString(value) > maxTagValueLength:
+			c.add(RuleInvalidTag, p+pointer(key), "%s: value of tag %q is longer than %d characters", owner, key, maxTagValueLength)
+		case !tagPattern.MatchString(value):
+			c.add(RuleInvalidTag, p+pointer(key), "%s: value of tag %q may only contain letters, numbers, spaces and _ . : / = + - @", owner, key)
+		}
+	}
+
+	if merged > maxTags && parent <= maxTags {
+		c.add(RuleInvalidTag, p, "%s: %d tags once merged with the tags of the levels above, AWS allows at most %d per resource", owner, merged, maxTags)
+	}
+}
@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeTags(t *testing.T) {
+	assert.Nil(t, MergeTags(nil, map[string]TrimmedString{}))
+	assert.Equal(t, map[string]TrimmedString{"Owner": "api-team", "Environment": "staging", "Project": "api"}, MergeTags(
+		map[string]TrimmedString{"Owner": "platform-team", "Environment": "staging"},
+		nil,
+		map[string]TrimmedString{"Owner": "api-team", "Project": "api"},
+	))
+}
+
+func TestValidateTags(t *testing.T) {
+	const base = `name: api-cluster
+tags: {Owner: platform-team}
+infrastructure:
+  - {id: aws, kind: vm, provider: aws, region: eu-central-1, tags: {Environment: staging}}
+nodes:
+  - {id: node1, infrastructure_id: aws, properties: {instance_type: t3.large}, remote_access: {public_ssh_key: ~/.ssh/id.pub}}
+devcontainers:
+  - id: api
+    node_id: node1
+    source: {url: "https://github.com/example/api.git"}
+    tags: %s
+`
+
+	t.Run("valid tags", func(t *testing.T) {
+		errs, err := Validate([]byte(fmt.Sprintf(base, `{Project: api, "team:owner": "api@example.com"}`)), WithFormat(FormatYAML))
+		require.NoError(t, err)
+		assert.Empty(t, errs)
+	})
+
+	tooMany := make([]string, 49)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("Tag%02d: value", i)
+	}
+
+	tests := []struct {
+		name    string
+		tags    string
+		pointer string
+		message string
+	}{
+		{"reserved prefix", `{"aws:createdBy": api}`,
+			"/devcontainers/0/tags/aws:createdBy", `devcontainer "api": tag key "aws:createdBy" uses the prefix 'aws:', which is reserved by AWS`},
+		{"invalid key", `{"Project#1": api}`,
+			"/devcontainers/0/tags/Project#1", `devcontainer "api": tag key "Project#1" may only contain letters, numbers, spaces and _ . : / = + - @`},
+		{"key too long", fmt.Sprintf(`{%s: api}`, strings.Repeat("k", 129)),
+			"/devcontainers/0/tags/" + strings.Repeat("k", 129), fmt.Sprintf(`devcontainer "api": tag key %q must be 1 to 128 characters long`, strings.Repeat("k", 129))},
+		{"value too long", fmt.Sprintf(`{Project: %s}`, strings.Repeat("v", 257)),
+			"/devcontainers/0/tags/Project", `devcontainer "api": value of tag "Project" is longer than 256 characters`},
+		{"too many tags once merged", "{" + strings.Join(tooMany, ", ") + "}",
+			"/devcontainers/0/tags", `devcontainer "api": 51 tags once merged with the tags of the levels above, AWS allows at most 50 per resource`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := Validate([]byte(fmt.Sprintf(base, tt.tags)), WithFormat(FormatYAML))
+			require.NoError(t, err)
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, RuleInvalidTag, errs[0].Code)
+			assert.Equal(t, tt.pointer, errs[0].Pointer)
+			assert.Equal(t, tt.message, errs[0].Message)
+		})
+	}
+}
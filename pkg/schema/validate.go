@@ -0,0 +1,19 @@
+package schema
+
+// Validate runs every check performed by Parse and returns all problems found,
+// including those of warning severity. The error result is reserved for failures
+// unrelated to the data itself.
+func Validate(data []byte, opts ...Option) (ValidationErrors, error) {
+	_, errs, err := parse(data, newParseOptions(opts))
+	return errs, err
+}
+
+// ParseAndValidate runs Parse and Validate in a single pass: it returns all problems found, including
+// those of warning severity, and the model Parse would return when none of them is an error, nil otherwise.
+func ParseAndValidate(data []byte, opts ...Option) (*DenvclustrRoot, ValidationErrors, error) {
+	root, errs, err := parse(data, newParseOptions(opts))
+	if err != nil || errs.HasErrors() {
+		return nil, errs, err
+	}
+	return root, errs, nil
+}
@@ -0,0 +1,201 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Enum of validation error severities.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Rule codes reported by ValidationError. Errors found by the JSON schema
+// use the RuleSchemaPrefix followed by the failing schema keyword, e.g. "schema/required".
+const (
+	RuleSyntax                     = "syntax"
+	RuleUnsupportedVersion         = "unsupported-version"
+	RuleInvalidOverlay             = "invalid-overlay"
+	RuleInvalidInclude             = "invalid-include"
+	RuleUndeclaredVariable         = "undeclared-variable"
+	RuleUndefinedVariable          = "undefined-variable"
+	RuleInvalidInterpolation       = "invalid-interpolation"
+	RuleSchemaPrefix               = "schema/"
+	RuleMissingField               = "missing-field"
+	RuleDuplicateId                = "duplicate-id"
+	RuleDuplicateInfrastructure    = "duplicate-infrastructure"
+	RuleUnreferencedInfrastructure = "unreferenced-infrastructure"
+	RuleUnreferencedNode           = "unreferenced-node"
+	RuleUnknownNode                = "unknown-node"
+	RuleSSHKeyRequired             = "ssh-key-required"
+	RuleSSHKeyForbidden            = "ssh-key-forbidden"
+	RuleConflictingFields          = "conflicting-fields"
+	RulePortOutOfRange             = "port-out-of-range"
+	RulePortConflict               = "port-conflict"
+	RulePortWithDNS                = "port-with-dns"
+	RuleUnknownRegion              = "unknown-region"
+	RuleUnknownInstanceType        = "unknown-instance-type"
+	RuleInvalidSSHKey              = "invalid-ssh-key"
+	RuleInvalidGitURL              = "invalid-git-url"
+	RuleCredentialsInURL           = "credentials-in-url"
+	RuleInvalidImage               = "invalid-image"
+	RuleInvalidPath                = "invalid-path"
+	RuleDuplicateEnv               = "duplicate-env"
+	RuleSecretInEnv                = "secret-in-env"
+	RuleInvalidTag                 = "invalid-tag"
+	RuleArchitectureMismatch       = "architecture-mismatch"
+	RuleInvalidCIDR                = "invalid-cidr"
+	RuleInvalidHost                = "invalid-host"
+	RuleInvalidSchedule            = "invalid-schedule"
+	RuleInvalidExpiry              = "invalid-expiry"
+)
+
+// ValidationError describes a single problem found in a denvclustr file.
+type ValidationError struct {
+	// Code identifies the rule that was violated.
+	Code string `json:"code"`
+	// Pointer is the JSON pointer (RFC 6901) of the offending value.
+	Pointer string `json:"pointer"`
+	// File is the overlay file the offending value comes from. It is empty when
+	// the value comes from the parsed file itself.
+	File string `json:"file,omitempty"`
+	// Line and Column locate the offending value in the original file. Both are 1-based
+	// and zero when the position is unknown.
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	var location []string
+	if e.Pointer != "" {
+		location = append(location, e.Pointer)
+	}
+	switch {
+	case e.File != "" && e.Line > 0:
+		location = append(location, fmt.Sprintf("%s, line %d, column %d", e.File, e.Line, e.Column))
+	case e.File != "":
+		location = append(location, e.File)
+	case e.Line > 0:
+		location = append(location, fmt.Sprintf("line %d, column %d", e.Line, e.Column))
+	}
+
+	switch len(location) {
+	case 0:
+		return e.Message
+	case 1:
+		return fmt.Sprintf("%s: %s", location[0], e.Message)
+	default:
+		return fmt.Sprintf("%s (%s): %s", location[0], location[1], e.Message)
+	}
+}
+
+// ValidationErrors lists every problem found in a denvclustr file.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d validation problems found:", len(e))
+	for _, err := range e {
+		fmt.Fprintf(&sb, "\n  - %s", err.Error())
+	}
+	return sb.String()
+}
+
+// HasErrors reports whether the list contains at least one entry of error severity.
+func (e ValidationErrors) HasErrors() bool {
+	for _, err := range e {
+		if err.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// sortByPosition orders the errors by their position in the file, errors in the parsed file coming
+// before errors in overlays. Errors without a position keep their relative order and come first.
+func (e ValidationErrors) sortByPosition() {
+	sort.SliceStable(e, func(i, j int) bool {
+		if e[i].File != e[j].File {
+			return e[i].File < e[j].File
+		}
+		if e[i].Line != e[j].Line {
+			return e[i].Line < e[j].Line
+		}
+		return e[i].Column < e[j].Column
+	})
+}
+
+// collector accumulates validation errors in the order they are found.
+type collector struct {
+	errs ValidationErrors
+	// origin returns the file a value comes from, empty for the parsed file itself.
+	origin func(pointer string) string
+	// replicas are the definitions the devcontainers come from, when replicas were expanded.
+	replicas []replicaOrigin
+}
+
+func (c *collector) add(code, pointer, format string, args ...any) {
+	c.report(SeverityError, code, pointer, format, args...)
+}
+
+func (c *collector) warn(code, pointer, format string, args ...any) {
+	c.report(SeverityWarning, code, pointer, format, args...)
+}
+
+func (c *collector) report(severity Severity, code, pointer, format string, args ...any) {
+	c.errs = append(c.errs, &ValidationError{
+		Code:     code,
+		Pointer:  pointer,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// devcontainer names the devcontainer at index i of the devcontainers array in messages, e.g. `devcontainer "api"`.
+// Replicas are named after their definition, since their own ids do not appear in the file.
+func (c *collector) devcontainer(i int, devcontainer *Devcontainer) string {
+	if i < len(c.replicas) {
+		return fmt.Sprintf("devcontainer %q", c.replicas[i].id)
+	}
+	return fmt.Sprintf("devcontainer %q", devcontainer.Id)
+}
+
+// replica names the devcontainer at index i of the devcontainers array like devcontainer, except that
+// replicas are named by their position in their definition, e.g. `replica 2 of "api"`, for the messages
+// about values that differ between replicas, such as their ports.
+func (c *collector) replica(i int, devcontainer *Devcontainer) string {
+	if i < len(c.replicas) && c.replicas[i].replica > 0 {
+		return fmt.Sprintf("replica %d of %q", c.replicas[i].replica, c.replicas[i].id)
+	}
+	return c.devcontainer(i, devcontainer)
+}
+
+// definedIn describes where two conflicting definitions come from, when at least one of them
+// comes from an included fragment or an overlay. It returns an empty string otherwise.
+func (c *collector) definedIn(p, first string) string {
+	if c.origin == nil {
+		return ""
+	}
+	file, firstFile := c.origin(p), c.origin(first)
+	if file == "" && firstFile == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (defined in %s, first defined in %s)", describeFile(file), describeFile(firstFile))
+}
+
+func describeFile(file string) string {
+	if file == "" {
+		return "the main file"
+	}
+	return file
+}
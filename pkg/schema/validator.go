@@ -2,11 +2,14 @@ package schema
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 
 	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 )
 
 var (
@@ -44,35 +47,74 @@ func getValidator() (*jsonschema.Schema, error) {
 	return validator, validatorErr
 }
 
-func validateSchema(data any) error {
+var schemaMessagePrinter = message.NewPrinter(language.English)
+
+// validateSchema validates data against the JSON schema. Every failing leaf of the
+// schema validation is reported as a separate ValidationError.
+func validateSchema(data any) (ValidationErrors, error) {
 	validator, err := getValidator()
 	if err != nil {
-		return fmt.Errorf("get validator: %w", err)
+		return nil, fmt.Errorf("get validator: %w", err)
+	}
+
+	err = validator.Validate(data)
+	if err == nil {
+		return nil, nil
 	}
 
-	if err := validator.Validate(data); err != nil {
-		return fmt.Errorf("schema validation failed: %w", err)
+	var schemaErr *jsonschema.ValidationError
+	if !errors.As(err, &schemaErr) {
+		return nil, fmt.Errorf("schema validation failed: %w", err)
 	}
 
-	return nil
+	c := &collector{}
+	collectSchemaErrors(schemaErr, c)
+	return c.errs, nil
 }
 
-// Validate a fully‑deserialized spec.
-func validateDeserialized(root *DenvclustrRoot) error {
-	if err := validateInfrastructure(root); err != nil {
-		return err
+func collectSchemaErrors(err *jsonschema.ValidationError, c *collector) {
+	if len(err.Causes) == 0 {
+		c.add(
+			RuleSchemaPrefix+strings.Join(err.ErrorKind.KeywordPath(), "/"),
+			pointer(toAny(err.InstanceLocation)...),
+			"%s", err.ErrorKind.LocalizedString(schemaMessagePrinter),
+		)
+		return
 	}
-	if err := validateNodes(root); err != nil {
-		return err
+	for _, cause := range err.Causes {
+		collectSchemaErrors(cause, c)
 	}
-	if err := validateDevcontainers(root); err != nil {
-		return err
+}
+
+func toAny(tokens []string) []any {
+	result := make([]any, len(tokens))
+	for i, token := range tokens {
+		result[i] = token
 	}
-	return nil
+	return result
 }
 
-func validateInfrastructure(root *DenvclustrRoot) error {
-	seenIds := make(map[string]struct{})
+// Validate a fully‑deserialized spec. Regions and instance types are checked against the catalog.
+// The origin function, which may be nil, names the fragment each value comes from in the messages about duplicated ids.
+// The replicas, which may be nil, name expanded devcontainers after the definition they come from.
+func validateDeserialized(root *DenvclustrRoot, catalog Catalog, origin func(pointer string) string, replicas []replicaOrigin) ValidationErrors {
+	c := &collector{origin: origin, replicas: replicas}
+	validateInfrastructure(root, c)
+	validateNodes(root, c)
+	validateCatalog(root, catalog, c)
+	validateNodeProperties(root, catalog, c)
+	validateNetworks(root, c)
+	validateAccessModes(root, c)
+	validateSchedules(root, c)
+	validateDevcontainers(root, c)
+	validatePorts(root, c)
+	validateTags(root, c)
+	validateExpiries(root, c)
+	return c.errs
+}
+
+func validateInfrastructure(root *DenvclustrRoot, c *collector) {
+	seenIds := make(map[string]int)
 	type tuple struct {
 		Kind     InfrastructureKind
 		Provider Provider
@@ -84,24 +126,30 @@ func validateInfrastructure(root *DenvclustrRoot) error {
 		id := string(infrastructure.Id)
 
 		if id == "" {
-			return fmt.Errorf("infrastructure[%d]: id is missing", i)
+			c.add(RuleMissingField, pointer("infrastructure", i, "id"), "infrastructure[%d]: id is missing", i)
+			continue
 		}
 
-		if _, exists := seenIds[id]; exists {
-			return fmt.Errorf("infrastructure id %q is duplicated", id)
+		if first, exists := seenIds[id]; exists {
+			c.add(RuleDuplicateId, pointer("infrastructure", i, "id"), "infrastructure id %q is duplicated%s", id,
+				c.definedIn(pointer("infrastructure", i, "id"), pointer("infrastructure", first, "id")),
+			)
+			continue
 		}
-		seenIds[id] = struct{}{}
+		seenIds[id] = i
 
 		if infrastructure.Region == "" {
-			return fmt.Errorf("infrastructure %q: region is missing", id)
+			c.add(RuleMissingField, pointer("infrastructure", i, "region"), "infrastructure %q: region is missing", id)
+			continue
 		}
 
 		t := tuple{infrastructure.Kind, infrastructure.Provider, string(infrastructure.Region)}
 		if previousId, exists := seenTuple[t]; exists {
-			return fmt.Errorf(
+			c.add(RuleDuplicateInfrastructure, pointer("infrastructure", i),
 				"infrastructure %q: duplicate combination of kind %q, provider %q, region %q (also defined by %q)",
 				id, infrastructure.Kind, infrastructure.Provider, infrastructure.Region, previousId,
 			)
+			continue
 		}
 		seenTuple[t] = id
 	}
@@ -111,43 +159,53 @@ func validateInfrastructure(root *DenvclustrRoot) error {
 	for _, node := range root.Nodes {
 		referenced[string(node.InfrastructureId)] = struct{}{}
 	}
-	for id := range seenIds {
-		if _, ok := referenced[id]; !ok {
-			return fmt.Errorf("infrastructure %q is not referenced by any node", id)
+	reported := make(map[string]struct{})
+	for i, infrastructure := range root.Infrastructure {
+		id := string(infrastructure.Id)
+		if _, ok := referenced[id]; ok || id == "" {
+			continue
+		}
+		if _, ok := reported[id]; ok {
+			continue
 		}
+		reported[id] = struct{}{}
+		c.add(RuleUnreferencedInfrastructure, pointer("infrastructure", i), "infrastructure %q is not referenced by any node", id)
 	}
-	return nil
 }
 
-func validateNodes(root *DenvclustrRoot) error {
-	seen := make(map[string]struct{})
+func validateNodes(root *DenvclustrRoot, c *collector) {
+	seen := make(map[string]int)
 
 	for i, node := range root.Nodes {
 		id := string(node.Id)
 
 		if id == "" {
-			return fmt.Errorf("node[%d]: id is missing", i)
+			c.add(RuleMissingField, pointer("nodes", i, "id"), "node[%d]: id is missing", i)
+			continue
 		}
 
-		if _, exists := seen[id]; exists {
-			return fmt.Errorf("node id %q is duplicated", id)
+		if first, exists := seen[id]; exists {
+			c.add(RuleDuplicateId, pointer("nodes", i, "id"), "node id %q is duplicated%s", id,
+				c.definedIn(pointer("nodes", i, "id"), pointer("nodes", first, "id")),
+			)
+			continue
 		}
-		seen[id] = struct{}{}
+		seen[id] = i
 
 		if node.InfrastructureId == "" {
-			return fmt.Errorf("node %q: infrastructure_id is missing", id)
+			c.add(RuleMissingField, pointer("nodes", i, "infrastructure_id"), "node %q: infrastructure_id is missing", id)
 		}
 
 		if node.Properties.InstanceType == "" {
-			return fmt.Errorf("node %q: instance_type is missing", id)
+			c.add(RuleMissingField, pointer("nodes", i, "properties", "instance_type"), "node %q: instance_type is missing", id)
 		}
 
 		if node.RemoteAccess.PublicSSHKey == "" {
-			return fmt.Errorf("node %q: public_ssh_key is missing", id)
+			c.add(RuleMissingField, pointer("nodes", i, "remote_access", "public_ssh_key"), "node %q: public_ssh_key is missing", id)
 		}
 
 		if node.DNS != nil && node.DNS.HighLevelDomain == "" {
-			return fmt.Errorf(
+			c.add(RuleMissingField, pointer("nodes", i, "dns", "high_level_domain"),
 				"node %q: dns.high_level_domain must be provided when DNS settings exist", id,
 			)
 		}
@@ -158,51 +216,93 @@ func validateNodes(root *DenvclustrRoot) error {
 	for _, devcontainer := range root.Devcontainers {
 		referenced[string(devcontainer.NodeId)] = struct{}{}
 	}
-	for id := range seen {
-		if _, ok := referenced[id]; !ok {
-			return fmt.Errorf("node %q is not referenced by any devcontainer", id)
+	reported := make(map[string]struct{})
+	for i, node := range root.Nodes {
+		id := string(node.Id)
+		if _, ok := referenced[id]; ok || id == "" {
+			continue
 		}
+		if _, ok := reported[id]; ok {
+			continue
+		}
+		reported[id] = struct{}{}
+		c.add(RuleUnreferencedNode, pointer("nodes", i), "node %q is not referenced by any devcontainer", id)
 	}
-	return nil
 }
 
-func validateDevcontainers(root *DenvclustrRoot) error {
-	seen := make(map[string]struct{})
+func validateDevcontainers(root *DenvclustrRoot, c *collector) {
+	seen := make(map[string]int)
 	nodeMap := collectNodeMap(root)
 
 	for i, devcontainer := range root.Devcontainers {
 		id := string(devcontainer.Id)
 
 		if id == "" {
-			return fmt.Errorf("devcontainer[%d]: id is missing", i)
+			c.add(RuleMissingField, pointer("devcontainers", i, "id"), "devcontainer[%d]: id is missing", i)
+			continue
 		}
 
-		if _, exists := seen[id]; exists {
-			return fmt.Errorf("devcontainer id %q is duplicated", id)
+		if first, exists := seen[id]; exists {
+			c.add(RuleDuplicateId, pointer("devcontainers", i, "id"), "devcontainer id %q is duplicated%s", id,
+				c.definedIn(pointer("devcontainers", i, "id"), pointer("devcontainers", first, "id")),
+			)
+			continue
 		}
-		seen[id] = struct{}{}
+		seen[id] = i
+		owner := c.devcontainer(i, devcontainer)
 
-		if devcontainer.NodeId == "" {
-			return fmt.Errorf("devcontainer %q: node_id is missing", id)
+		if devcontainer.Replicas != nil && len(devcontainer.ForEach) > 0 {
+			c.add(RuleConflictingFields, pointer("devcontainers", i, "for_each"), "%s: replicas and for_each cannot be used together", owner)
 		}
 
-		if _, ok := nodeMap[string(devcontainer.NodeId)]; !ok {
-			return fmt.Errorf("devcontainer %q: refers to unknown node_id %q", id, devcontainer.NodeId)
+		// Ports of expanded replicas are incremented, so they are checked again here
+		if devcontainer.RemoteAccess != nil {
+			for _, port := range []struct {
+				name  string
+				value *int
+			}{
+				{"openvscode_server", openVSCodeServerPort(devcontainer.RemoteAccess.OpenVsCodeServer)},
+				{"ssh", sshPort(devcontainer.RemoteAccess.Ssh)},
+			} {
+				if port.value == nil || (*port.value >= minPort && *port.value <= maxPort) {
+					continue
+				}
+				p := pointer("devcontainers", i, "remote_access", port.name, "port")
+				if replica := c.replica(i, devcontainer); replica != owner {
+					c.add(RulePortOutOfRange, p, "%s would use %s port %d, out of range (%d-%d)", replica, port.name, *port.value, minPort, maxPort)
+				} else {
+					c.add(RulePortOutOfRange, p, "%s: %s port %d is out of range (%d-%d)", owner, port.name, *port.value, minPort, maxPort)
+				}
+			}
 		}
 
-		if devcontainer.Source == nil || devcontainer.Source.URL == "" {
-			return fmt.Errorf("devcontainer %q: source.url is required and must be valid", id)
+		if devcontainer.NodeId == "" {
+			c.add(RuleMissingField, pointer("devcontainers", i, "node_id"), "%s: node_id is missing", owner)
+		} else if _, ok := nodeMap[string(devcontainer.NodeId)]; !ok {
+			c.add(RuleUnknownNode, pointer("devcontainers", i, "node_id"), "%s: refers to unknown node_id %q", owner, devcontainer.NodeId)
 		}
 
-		isSSH := strings.HasPrefix(string(devcontainer.Source.URL), "ssh://") || strings.HasPrefix(string(devcontainer.Source.URL), "git@")
+		validateAdditionalRepositories(devcontainer, i, c)
+		validateEnvironment(devcontainer, i, c)
 
-		if isSSH && devcontainer.Source.SshKey == nil {
-			return fmt.Errorf("devcontainer %q: ssh_key must be provided for SSH-based URLs", id)
+		// A prebuilt image replaces the repository the devcontainer is built from
+		if devcontainer.Image != nil {
+			if devcontainer.Source != nil {
+				c.add(RuleConflictingFields, pointer("devcontainers", i, "image"), "%s: image and source cannot be used together", owner)
+			}
+			validateImage(devcontainer, i, c)
+			continue
 		}
 
-		if !isSSH && devcontainer.Source.SshKey != nil {
-			return fmt.Errorf("devcontainer %q: ssh_key must not be used with non-SSH URLs", id)
+		if devcontainer.Source == nil || devcontainer.Source.URL == "" {
+			c.add(RuleMissingField, pointer("devcontainers", i, "source", "url"), "%s: source.url is required and must be valid", owner)
+			continue
 		}
+
+		p := pointer("devcontainers", i, "source")
+		validateRevision(c, p, owner, []revisionField{
+			{"branch", devcontainer.Source.Branch}, {"tag", devcontainer.Source.Tag}, {"commit", devcontainer.Source.Commit},
+		})
+		validateRepositoryAccess(c, p, owner, "source.url", devcontainer.Source.URL, devcontainer.Source.SshKey)
 	}
-	return nil
 }
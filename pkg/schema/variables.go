@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// variablesKey is the name of the top-level property declaring variables.
+const variablesKey = "variables"
+
+// Namespaces of interpolation references.
+const (
+	variableNamespace    = "var"
+	environmentNamespace = "env"
+)
+
+// interpolationPattern matches escaped references ("$${") and references such as "${var.region}".
+var interpolationPattern = regexp.MustCompile(`\$\$\{|\$\{([^}]*)\}`)
+
+var referenceNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// resolveVariables replaces the "${var.NAME}" and "${env.NAME}" references found in
+// the string values of a document. Variables take their value from the options first
+// and fall back to the default declared in the variables block. "$${" produces a literal "${".
+func resolveVariables(doc *document, options *parseOptions) ValidationErrors {
+	if doc.root.Kind != yaml.MappingNode {
+		return nil
+	}
+	c := &collector{}
+
+	declared := make(map[string]*yaml.Node)
+	_, block := childNode(doc.root, variablesKey)
+	if block != nil && block.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(block.Content); i += 2 {
+			declared[block.Content[i].Value] = resolveAlias(block.Content[i+1])
+		}
+	}
+
+	values := make(map[string]string)
+	for name, node := range declared {
+		if node.Kind == yaml.ScalarNode && node.Tag != "!!null" {
+			// Numbers and booleans are accepted as defaults and used as strings
+			node.Tag = "!!str"
+			values[name] = node.Value
+		}
+	}
+
+	names := make([]string, 0, len(options.variables))
+	for name := range options.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := declared[name]; !ok {
+			c.add(RuleUndeclaredVariable, pointer(variablesKey), "variable %q is given a value but is not declared in the variables block", name)
+			continue
+		}
+		values[name] = options.variables[name]
+	}
+
+	for i := 0; i+1 < len(doc.root.Content); i += 2 {
+		key := doc.root.Content[i].Value
+		if key == variablesKey {
+			continue
+		}
+		interpolateNode(doc.root.Content[i+1], []any{key}, declared, values, options, c)
+	}
+	return c.errs
+}
+
+func interpolateNode(node *yaml.Node, tokens []any, declared map[string]*yaml.Node, values map[string]string, options *parseOptions, c *collector) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			interpolateNode(node.Content[i+1], append(tokens, node.Content[i].Value), declared, values, options, c)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			interpolateNode(item, append(tokens, i), declared, values, options, c)
+		}
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" || !strings.Contains(node.Value, "${") {
+			return
+		}
+		node.Value = interpolationPattern.ReplaceAllStringFunc(node.Value, func(match string) string {
+			if match == "$${" {
+				return "${"
+			}
+
+			reference := match[2 : len(match)-1]
+			namespace, name, found := strings.Cut(strings.TrimSpace(reference), ".")
+			if !found || !referenceNamePattern.MatchString(name) {
+				c.add(RuleInvalidInterpolation, pointer(tokens...), "invalid reference %q, expected ${var.NAME} or ${env.NAME}", match)
+				return match
+			}
+
+			switch namespace {
+			case variableNamespace:
+				if value, ok := values[name]; ok {
+					return value
+				}
+				if _, ok := declared[name]; ok {
+					c.add(RuleUndefinedVariable, pointer(tokens...), "variable %q has no default and no value was given", name)
+				} else {
+					c.add(RuleUndefinedVariable, pointer(tokens...), "variable %q is not declared in the variables block", name)
+				}
+			case environmentNamespace:
+				if value, ok := options.lookupEnv(name); ok {
+					return value
+				}
+				c.add(RuleUndefinedVariable, pointer(tokens...), "environment variable %q is not set", name)
+			default:
+				c.add(RuleInvalidInterpolation, pointer(tokens...), "unknown reference namespace %q in %q, expected \"var\" or \"env\"", namespace, match)
+			}
+			return match
+		})
+	}
+}
+
+// ParseVariables reads variable values from a variables file: a JSON, JSONC or YAML
+// object mapping variable names to scalar values.
+func ParseVariables(data []byte, format Format) (map[string]string, error) {
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if doc.root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("variables file must contain an object")
+	}
+
+	values := make(map[string]string)
+	for i := 0; i+1 < len(doc.root.Content); i += 2 {
+		name, value := doc.root.Content[i].Value, resolveAlias(doc.root.Content[i+1])
+		if value.Kind != yaml.ScalarNode || value.Tag == "!!null" {
+			return nil, fmt.Errorf("variable %q must have a string, number or boolean value", name)
+		}
+		values[name] = value.Value
+	}
+	return values, nil
+}
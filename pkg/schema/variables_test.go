@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveVariables(t *testing.T) {
+	data, err := testDataFS.ReadFile("testdata/variables.yaml")
+	require.NoError(t, err, "Failed to read test file")
+
+	lookupEnv := func(name string) (string, bool) {
+		if name == "HOME" {
+			return "/home/dev", true
+		}
+		return "", false
+	}
+
+	t.Run("values come from options, defaults and the environment", func(t *testing.T) {
+		root, err := Parse(data,
+			WithFormat(FormatYAML),
+			WithLookupEnv(lookupEnv),
+			WithVariables(map[string]string{"region": "eu-west-1", "instance_type": "t3.small"}),
+			WithVariables(map[string]string{"instance_type": "t3.large"}),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, TrimmedString("platform-cluster"), root.Name)
+		assert.Equal(t, TrimmedString("eu-west-1"), root.Infrastructure[0].Region)
+		assert.Equal(t, TrimmedString("t3.large"), root.Nodes[0].Properties.InstanceType)
+		assert.Equal(t, TrimmedString("/home/dev/.ssh/id_rsa.pub"), root.Nodes[0].RemoteAccess.PublicSSHKey)
+		assert.Equal(t, TrimmedString("${not.a.reference}"), root.Devcontainers[0].Source.Branch)
+		assert.Equal(t, TrimmedString("2222"), root.Variables["port"])
+	})
+
+	t.Run("missing and undeclared values are validation errors", func(t *testing.T) {
+		_, err := Parse(data,
+			WithFormat(FormatYAML),
+			WithLookupEnv(func(string) (string, bool) { return "", false }),
+			WithVariables(map[string]string{"zone": "a"}),
+		)
+
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 3)
+		assert.Equal(t, &ValidationError{Code: RuleUndeclaredVariable, Pointer: "/variables", Line: 2, Column: 1, Severity: SeverityError, Message: `variable "zone" is given a value but is not declared in the variables block`}, errs[0])
+		assert.Equal(t, &ValidationError{Code: RuleUndefinedVariable, Pointer: "/infrastructure/0/region", Line: 11, Column: 5, Severity: SeverityError, Message: `variable "region" has no default and no value was given`}, errs[1])
+		assert.Equal(t, &ValidationError{Code: RuleUndefinedVariable, Pointer: "/nodes/0/remote_access/public_ssh_key", Line: 18, Column: 7, Severity: SeverityError, Message: `environment variable "HOME" is not set`}, errs[2])
+	})
+}
+
+func TestInterpolationErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"undeclared variable", "${var.unknown}", RuleUndefinedVariable},
+		{"unknown namespace", "${local.name}", RuleInvalidInterpolation},
+		{"missing namespace", "${name}", RuleInvalidInterpolation},
+		{"invalid name", "${var.}", RuleInvalidInterpolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(`{"name": "` + tt.value + `", "infrastructure": [], "nodes": [], "devcontainers": []}`)
+
+			errs, err := Validate(data)
+			require.NoError(t, err)
+			require.NotEmpty(t, errs)
+			assert.Equal(t, tt.expected, errs[0].Code)
+			assert.Equal(t, "/name", errs[0].Pointer)
+		})
+	}
+}
+
+func TestParseVariables(t *testing.T) {
+	values, err := ParseVariables([]byte("region: eu-west-1\nport: 2222\nenabled: true\n"), FormatYAML)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "eu-west-1", "port": "2222", "enabled": "true"}, values)
+
+	_, err = ParseVariables([]byte(`{"region": ["eu-west-1"]}`), FormatJSON)
+	assert.Error(t, err)
+
+	_, err = ParseVariables([]byte(`["eu-west-1"]`), FormatJSON)
+	assert.Error(t, err)
+}
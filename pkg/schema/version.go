@@ -0,0 +1,140 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported versions of the denvclustr file format.
+const (
+	Version1 = "1"
+
+	// CurrentVersion is the version described by GetSchema and produced by Migrate.
+	CurrentVersion = Version1
+)
+
+// versionKey is the name of the top-level property holding the file format version.
+const versionKey = "version"
+
+// migration upgrades a document from one version of the file format to the next one.
+type migration struct {
+	from  string
+	to    string
+	apply func(root *yaml.Node) error
+}
+
+// migrations lists the upgrade steps between consecutive versions, oldest first.
+var migrations []migration
+
+// MigrationResult describes the outcome of Migrate.
+type MigrationResult struct {
+	// FromVersion is the version the file was written in.
+	FromVersion string
+	// ToVersion is the version of the migrated file.
+	ToVersion string
+	// Data is the migrated file, in the same format as the input.
+	Data []byte
+	// Changed reports whether Data differs from the input.
+	Changed bool
+}
+
+// Migrate upgrades a denvclustr file to CurrentVersion and records the version explicitly.
+// The result keeps the format of the input. Comments are preserved in YAML files,
+// while JSONC files are rewritten as plain JSON.
+func Migrate(data []byte, format Format) (*MigrationResult, error) {
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	fromVersion, explicit, err := documentVersion(doc.root)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateDocument(doc.root, fromVersion); err != nil {
+		return nil, err
+	}
+
+	result := &MigrationResult{FromVersion: fromVersion, ToVersion: CurrentVersion, Data: data}
+	if fromVersion == CurrentVersion && explicit {
+		return result, nil
+	}
+
+	migrated, err := doc.encode()
+	if err != nil {
+		return nil, err
+	}
+	result.Data = migrated
+	result.Changed = !bytes.Equal(migrated, data)
+	return result, nil
+}
+
+// documentVersion returns the version declared by a document. Documents without
+// a version are read as version 1, the format in use before versioning was introduced.
+func documentVersion(root *yaml.Node) (string, bool, error) {
+	_, value := childNode(root, versionKey)
+	if value == nil {
+		return Version1, false, nil
+	}
+	if value.Kind != yaml.ScalarNode {
+		return "", true, fmt.Errorf("version must be a string")
+	}
+	if _, ok := schemaVersions[value.Value]; !ok {
+		return "", true, fmt.Errorf("unsupported version %q (supported versions: %v)", value.Value, SupportedVersions())
+	}
+	return value.Value, true, nil
+}
+
+// migrateDocument applies every migration needed to bring a document from the given
+// version to CurrentVersion, and sets the version property accordingly.
+func migrateDocument(root *yaml.Node, version string) error {
+	for version != CurrentVersion {
+		step := findMigration(version)
+		if step == nil {
+			return fmt.Errorf("no migration available from version %q", version)
+		}
+		if err := step.apply(root); err != nil {
+			return fmt.Errorf("migrate from version %q to %q: %w", step.from, step.to, err)
+		}
+		version = step.to
+	}
+
+	setVersion(root, CurrentVersion)
+	return nil
+}
+
+func findMigration(from string) *migration {
+	for i := range migrations {
+		if migrations[i].from == from {
+			return &migrations[i]
+		}
+	}
+	return nil
+}
+
+// setVersion stores the version as a string, adding the property first in the mapping if it is missing.
+func setVersion(root *yaml.Node, version string) {
+	if _, value := childNode(root, versionKey); value != nil {
+		value.Kind, value.Tag, value.Value, value.Style = yaml.ScalarNode, "!!str", version, 0
+		return
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: versionKey}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: version}
+	// Keep a leading comment of the document above the new property
+	if len(root.Content) > 0 {
+		key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	root.Content = append([]*yaml.Node{key, value}, root.Content...)
+}
+
+// compareVersions orders versions numerically.
+func compareVersions(a, b string) int {
+	x, _ := strconv.Atoi(a)
+	y, _ := strconv.Atoi(b)
+	return x - y
+}
@@ -0,0 +1,67 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Run("unversioned JSON gets an explicit version", func(t *testing.T) {
+		result, err := Migrate([]byte(`{"name": "cluster", "nodes": []}`), FormatJSON)
+		require.NoError(t, err)
+		assert.True(t, result.Changed)
+		assert.Equal(t, Version1, result.FromVersion)
+		assert.Equal(t, CurrentVersion, result.ToVersion)
+		assert.Equal(t, "{\n  \"version\": \"1\",\n  \"name\": \"cluster\",\n  \"nodes\": []\n}\n", string(result.Data))
+	})
+
+	t.Run("YAML comments are preserved", func(t *testing.T) {
+		result, err := Migrate([]byte("# cluster\nname: cluster # inline\n"), FormatYAML)
+		require.NoError(t, err)
+		assert.Equal(t, "# cluster\nversion: \"1\"\nname: cluster # inline\n", string(result.Data))
+	})
+
+	t.Run("current version is left untouched", func(t *testing.T) {
+		data := []byte(`{"version": "1", "name": "cluster"}`)
+		result, err := Migrate(data, FormatJSON)
+		require.NoError(t, err)
+		assert.False(t, result.Changed)
+		assert.Equal(t, data, result.Data)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := Migrate([]byte(`{"version": "99"}`), FormatJSON)
+		assert.ErrorContains(t, err, `unsupported version "99"`)
+	})
+
+	t.Run("migrations are chained up to the current version", func(t *testing.T) {
+		previousMigrations := migrations
+		schemaVersions["0"] = &schemaVersion{root: func() any { return &DenvclustrRoot{} }}
+		migrations = []migration{{from: "0", to: Version1, apply: func(root *yaml.Node) error {
+			key, _ := childNode(root, "title")
+			key.Value = "name"
+			return nil
+		}}}
+		t.Cleanup(func() {
+			migrations = previousMigrations
+			delete(schemaVersions, "0")
+		})
+
+		result, err := Migrate([]byte("version: \"0\"\ntitle: cluster\n"), FormatYAML)
+		require.NoError(t, err)
+		assert.Equal(t, "0", result.FromVersion)
+		assert.Equal(t, "version: \"1\"\nname: cluster\n", string(result.Data))
+	})
+}
+
+func TestGetSchemaForVersion(t *testing.T) {
+	schema, err := GetSchemaForVersion(CurrentVersion)
+	require.NoError(t, err)
+	assert.Same(t, GetSchema(), schema)
+
+	_, err = GetSchemaForVersion("99")
+	assert.Error(t, err)
+}